@@ -0,0 +1,17 @@
+# Requests to the gRPC server with grpcurl, it uses the server reflection
+
+grpcurl -plaintext 127.0.0.1:9000 list
+
+grpcurl -plaintext 127.0.0.1:9000 grpc.health.v1.Health/Check
+
+grpcurl -plaintext -d '{"page": {"limit": 5, "desc": true}, "filter": {"name": "go"}}' \
+    127.0.0.1:9000 restapi.v1.EventService/ListEvents
+
+grpcurl -plaintext -d '{"event": {"name": "GopherCon", "starts_at": "2022-07-01T09:00:00Z"}}' \
+    127.0.0.1:9000 restapi.v1.EventService/CreateEvent
+
+grpcurl -plaintext -d '{"event": 1, "participant": 2}' \
+    127.0.0.1:9000 restapi.v1.TicketService/CreateTicket
+
+grpcurl -plaintext -d '{"id": 1, "version": 2}' \
+    127.0.0.1:9000 restapi.v1.TicketService/DeleteTicket
@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+)
+
+// Entry of the audit log, a JSON line per request to a destructive route,
+// whether it was allowed or not
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Subject string    `json:"subject,omitempty"`
+	Role    string    `json:"role,omitempty"`
+	Address string    `json:"address"` // Of the connection, not X-Forwarded-For
+	Target  string    `json:"target"`
+	Status  int       `json:"status"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+var mu sync.Mutex
+
+// Record appends the entry to AUDIT_LOG, or writes it to the standard error
+func Record(e Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var w io.Writer = os.Stderr
+	if constants.AuditLog != "" {
+		f, err := os.OpenFile(constants.AuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return json.NewEncoder(w).Encode(e)
+}
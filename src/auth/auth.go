@@ -0,0 +1,103 @@
+// Package auth identifies the client of a request, with an API key stored
+// in the database or with a JWT bearer token. The middleware package
+// applies it to the HTTP routes and the rpc package to the gRPC calls.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderAPIKey = "X-API-Key"
+
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request
+	// doesn't carry the credentials it checks
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when the credentials are unknown,
+	// revoked, expired or badly signed
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated client of a request
+type Principal struct {
+	Subject string // key:<ID> of the API key or sub claim of the token
+	Name    string // Name of the API key or of the token, if any
+	Method  string // MethodAPIKey or MethodJWT
+	Role    Role
+}
+
+// Authenticator checks one kind of credentials, the headers are the ones
+// of the HTTP request or the metadata of a gRPC call
+type Authenticator interface {
+	Authenticate(ctx context.Context, h http.Header) (*Principal, error)
+}
+
+// Set up by Setup, the API keys only until then
+var defaults = []Authenticator{APIKeys{}}
+
+// Setup reads the keys of the tokens from the environment, once, before
+// the routes and the gRPC server take the default authenticators
+func Setup() error {
+	jwt, err := JWTFromEnv()
+	if err != nil {
+		return err
+	}
+	if jwt != nil {
+		defaults = []Authenticator{APIKeys{}, jwt}
+	}
+	return nil
+}
+
+// Default authenticators, the API keys and the tokens if Setup found a
+// key to verify them with
+func Default() []Authenticator {
+	return defaults
+}
+
+// Authenticate tries each authenticator until one of them finds its
+// credentials, ErrNoCredentials if none does
+func Authenticate(ctx context.Context, h http.Header, authenticators ...Authenticator) (*Principal, error) {
+	for _, a := range authenticators {
+		p, err := a.Authenticate(ctx, h)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+const principalKey = "principal"
+
+// WithPrincipal stores the principal in the echo context
+func WithPrincipal(c echo.Context, p *Principal) {
+	c.Set(principalKey, p)
+}
+
+// PrincipalOf the request, nil on the routes without authentication
+func PrincipalOf(c echo.Context) *Principal {
+	p, _ := c.Get(principalKey).(*Principal)
+	return p
+}
+
+type contextKey struct{}
+
+// NewContext carries the principal in a context, for the gRPC calls
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext is the principal of NewContext, nil if none
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// JWT authenticates the bearer tokens signed with HS256 or RS256, they
+// must be valid now, with an exp claim and a sub one. The role claim is
+// one of the roles, a viewer without it.
+type JWT struct {
+	Secret    []byte         // HS256 tokens are accepted if set
+	PublicKey *rsa.PublicKey // RS256 tokens are accepted if set
+	Issuer    string         // iss claim required if set
+	Audience  string         // One of the aud claims if set
+}
+
+// JWTFromEnv reads JWT_SECRET, JWT_PUBLIC_KEY (path to a PEM file),
+// JWT_ISSUER and JWT_AUDIENCE, nil if there's no key to verify the tokens
+// with
+func JWTFromEnv() (*JWT, error) {
+	var j = &JWT{
+		Secret:   []byte(os.Getenv("JWT_SECRET")),
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+	}
+
+	if path := os.Getenv("JWT_PUBLIC_KEY"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PUBLIC_KEY could not be read: %w", err)
+		}
+		if j.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+			return nil, fmt.Errorf("JWT_PUBLIC_KEY is not an RSA public key: %w", err)
+		}
+	}
+
+	if len(j.Secret) == 0 && j.PublicKey == nil {
+		return nil, nil
+	}
+	return j, nil
+}
+
+func (j *JWT) Authenticate(ctx context.Context, h http.Header) (*Principal, error) {
+	scheme, token, _ := strings.Cut(h.Get(echo.HeaderAuthorization), " ")
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := j.Parse(token)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	var (
+		p  = &Principal{Method: MethodJWT}
+		ok bool
+	)
+	p.Subject, _ = claims["sub"].(string)
+	p.Name, _ = claims["name"].(string)
+
+	role, _ := claims["role"].(string)
+	if p.Role, ok = ParseRole(role); !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return p, nil
+}
+
+// Parse verifies the token and returns its claims
+func (j *JWT) Parse(token string) (jwt.MapClaims, error) {
+	var (
+		claims  = make(jwt.MapClaims)
+		methods []string
+	)
+	if len(j.Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if j.PublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	parser := &jwt.Parser{ValidMethods: methods}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method == jwt.SigningMethodRS256 {
+			return j.PublicKey, nil
+		}
+		return j.Secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch sub, _ := claims["sub"].(string); {
+	case !claims.VerifyExpiresAt(time.Now().Unix(), true):
+		return nil, errors.New("the token has no exp claim")
+	case sub == "":
+		return nil, errors.New("the token has no sub claim")
+	case j.Issuer != "" && !claims.VerifyIssuer(j.Issuer, true):
+		return nil, errors.New("the token has another issuer")
+	case j.Audience != "" && !claims.VerifyAudience(j.Audience, true):
+		return nil, errors.New("the token is for another audience")
+	}
+	return claims, nil
+}
+
+// SignHS256 issues a token for the subject that expires after ttl, for
+// the setups without an identity provider
+func SignHS256(secret []byte, subject, name string, role Role, issuer, audience string, ttl time.Duration) (string, error) {
+	var (
+		now    = time.Now()
+		claims = jwt.MapClaims{"sub": subject, "role": string(role), "iat": now.Unix(), "exp": now.Add(ttl).Unix()}
+	)
+	if name != "" {
+		claims["name"] = name
+	}
+	if issuer != "" {
+		claims["iss"] = issuer
+	}
+	if audience != "" {
+		claims["aud"] = audience
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestJWTParse(t *testing.T) {
+	var (
+		secret = []byte("s3cret")
+		now    = time.Now()
+		valid  = func() jwt.MapClaims {
+			return jwt.MapClaims{"sub": "ana", "role": "staff", "exp": now.Add(time.Hour).Unix()}
+		}
+	)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshal(t, &key.PublicKey)})
+
+	tests := []struct {
+		name  string
+		jwt   JWT
+		token string
+		ok    bool
+	}{
+		{name: "HS256", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodHS256, secret, valid()), ok: true},
+		{name: "RS256", jwt: JWT{PublicKey: &key.PublicKey}, token: sign(t, jwt.SigningMethodRS256, key, valid()), ok: true},
+		{name: "alg none", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid())},
+		{name: "HS512", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodHS512, secret, valid())},
+		{name: "RS256 without a public key", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodRS256, key, valid())},
+		// The public key is no secret, a token signed with it as HMAC key is forged
+		{name: "HS256 with the public key", jwt: JWT{PublicKey: &key.PublicKey}, token: sign(t, jwt.SigningMethodHS256, public, valid())},
+		{name: "wrong secret", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodHS256, []byte("other"), valid())},
+		{name: "wrong private key", jwt: JWT{PublicKey: &key.PublicKey}, token: sign(t, jwt.SigningMethodRS256, otherKey(t), valid())},
+		{name: "tampered", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodHS256, secret, valid())[1:]},
+		{
+			name:  "expired",
+			jwt:   JWT{Secret: secret},
+			token: sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "ana", "exp": now.Add(-time.Minute).Unix()}),
+		},
+		{
+			name:  "not valid yet",
+			jwt:   JWT{Secret: secret},
+			token: sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "ana", "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Minute).Unix()}),
+		},
+		{name: "no exp", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "ana"})},
+		{name: "no sub", jwt: JWT{Secret: secret}, token: sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"exp": now.Add(time.Hour).Unix()})},
+		{name: "other issuer", jwt: JWT{Secret: secret, Issuer: "idp"}, token: sign(t, jwt.SigningMethodHS256, secret, with(valid(), "iss", "other"))},
+		{name: "issuer", jwt: JWT{Secret: secret, Issuer: "idp"}, token: sign(t, jwt.SigningMethodHS256, secret, with(valid(), "iss", "idp")), ok: true},
+		{name: "other audience", jwt: JWT{Secret: secret, Audience: "api"}, token: sign(t, jwt.SigningMethodHS256, secret, with(valid(), "aud", "web"))},
+		{name: "audience", jwt: JWT{Secret: secret, Audience: "api"}, token: sign(t, jwt.SigningMethodHS256, secret, with(valid(), "aud", "api")), ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.jwt.Parse(tt.token)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("Parse() = %v, want the claims", err)
+				}
+				if claims["sub"] != "ana" {
+					t.Errorf("sub = %v, want ana", claims["sub"])
+				}
+				return
+			}
+			if err == nil {
+				t.Errorf("Parse() = %v, want an error", claims)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	var (
+		secret = []byte("s3cret")
+		j      = &JWT{Secret: secret}
+		exp    = time.Now().Add(time.Hour).Unix()
+	)
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          *Principal
+		err           error
+	}{
+		{name: "no header", err: ErrNoCredentials},
+		{name: "basic", authorization: "Basic YW5hOnB3", err: ErrNoCredentials},
+		{name: "no token", authorization: "Bearer ", err: ErrNoCredentials},
+		{name: "bad token", authorization: "Bearer x.y.z", err: ErrInvalidCredentials},
+		{
+			name:          "staff",
+			authorization: "Bearer " + sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "ana", "name": "Ana", "role": "staff", "exp": exp}),
+			want:          &Principal{Subject: "ana", Name: "Ana", Method: MethodJWT, Role: Staff},
+		},
+		{
+			name:          "lowercase scheme, viewer without role",
+			authorization: "bearer " + sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "ana", "exp": exp}),
+			want:          &Principal{Subject: "ana", Method: MethodJWT, Role: Viewer},
+		},
+		{
+			name:          "unknown role",
+			authorization: "Bearer " + sign(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "ana", "role": "root", "exp": exp}),
+			err:           ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.authorization != "" {
+				h.Set("Authorization", tt.authorization)
+			}
+
+			p, err := j.Authenticate(context.Background(), h)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.err)
+			}
+			if tt.want != nil && (p == nil || *p != *tt.want) {
+				t.Errorf("Authenticate() = %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignHS256(t *testing.T) {
+	var (
+		secret = []byte("s3cret")
+		j      = &JWT{Secret: secret, Issuer: "idp", Audience: "api"}
+	)
+
+	token, err := SignHS256(secret, "ana", "Ana", Organizer, "idp", "api", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := http.Header{"Authorization": {"Bearer " + token}}
+	p, err := j.Authenticate(context.Background(), h)
+	if err != nil {
+		t.Fatalf("Authenticate() = %v", err)
+	}
+	if want := (Principal{Subject: "ana", Name: "Ana", Method: MethodJWT, Role: Organizer}); *p != want {
+		t.Errorf("Authenticate() = %+v, want %+v", p, want)
+	}
+
+	if token, err = SignHS256(secret, "ana", "", Organizer, "idp", "api", -time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = j.Parse(token); err == nil {
+		t.Error("Parse() accepted a token signed with a negative ttl")
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func with(claims jwt.MapClaims, name string, value interface{}) jwt.MapClaims {
+	claims[name] = value
+	return claims
+}
+
+func otherKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func mustMarshal(t *testing.T, key *rsa.PublicKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// KeyPrefix starts every API key, to tell them apart from other secrets
+const KeyPrefix = "rtt_"
+
+// APIKeys authenticates the X-API-Key header against the api_keys table,
+// only the SHA-256 of each key is stored
+type APIKeys struct {
+	DB database.Preparer // The pool shared by the API keys if nil
+}
+
+// Every request with an API key looks it up, the pool is opened by the
+// first one and kept for the next ones
+var keys struct {
+	sync.Mutex
+	db database.Connecter
+}
+
+func keysDB(ctx context.Context) (database.Preparer, error) {
+	keys.Lock()
+	defer keys.Unlock()
+
+	if keys.db == nil {
+		db := storage.Get(constants.Persistence)
+		if err := db.Connect(ctx); err != nil {
+			return nil, err
+		}
+		keys.db = db
+	}
+	return keys.db, nil
+}
+
+func (a APIKeys) Authenticate(ctx context.Context, h http.Header) (*Principal, error) {
+	key := h.Get(HeaderAPIKey)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	if !strings.HasPrefix(key, KeyPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deadline.Check)
+	defer cancel()
+
+	var db = a.DB
+	if db == nil {
+		var err error
+		if db, err = keysDB(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := LookupKey(ctx, db, key)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidCredentials
+	}
+	return p, err
+}
+
+// HashKey is the value of api_keys.key_hash for a key
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LookupKey finds the key among the ones that aren't revoked,
+// sql.ErrNoRows if there's none
+func LookupKey(ctx context.Context, db database.Preparer, key string) (*Principal, error) {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "SELECT id, name, role FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL;"
+	case storage.MySQL:
+		q = "SELECT id, name, role FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL;"
+	}
+
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var (
+		id   int64
+		role string
+		p    = &Principal{Method: MethodAPIKey}
+	)
+	if err = stmt.QueryRowContext(ctx, HashKey(key)).Scan(&id, &p.Name, &role); err != nil {
+		return nil, err
+	}
+	p.Role = Role(role)
+	p.Subject = "key:" + strconv.FormatInt(id, 10)
+	return p, nil
+}
+
+// CreateKey stores a new key with the role, the key itself is only
+// returned here
+func CreateKey(ctx context.Context, db database.Connecter, name string, role Role) (int64, string, error) {
+	var secret = make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return 0, "", err
+	}
+	key := KeyPrefix + hex.EncodeToString(secret)
+
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "INSERT INTO api_keys(name, key_hash, role) VALUES($1, $2, $3) RETURNING id;"
+	case storage.MySQL:
+		q = "INSERT INTO api_keys(name, key_hash, role) VALUES(?, ?, ?);"
+	}
+
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return 0, "", err
+	}
+	defer stmt.Close()
+
+	var id int64
+	if constants.Persistence == storage.PostgreSQL {
+		err = stmt.QueryRowContext(ctx, name, HashKey(key), role).Scan(&id)
+		return id, key, err
+	}
+
+	r, err := stmt.ExecContext(ctx, name, HashKey(key), role)
+	if err != nil {
+		return 0, "", err
+	}
+	id, err = r.LastInsertId()
+	return id, key, err
+}
+
+// RevokeKey stops accepting a key, false if there's no such key
+func RevokeKey(ctx context.Context, db database.Connecter, id int64) (bool, error) {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL;"
+	case storage.MySQL:
+		q = "UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL;"
+	}
+
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	r, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := r.RowsAffected()
+	return n > 0, err
+}
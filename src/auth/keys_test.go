@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHashKey(t *testing.T) {
+	// SHA-256 of "abc", FIPS 180-2
+	if got, want := HashKey("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Errorf("HashKey(abc) = %s, want %s", got, want)
+	}
+}
+
+func TestAPIKeysAuthenticate(t *testing.T) {
+	const key = KeyPrefix + "0123456789abcdef"
+
+	db := keysTable{HashKey(key): {id: 7, name: "door", role: "staff"}}.open(t)
+
+	tests := []struct {
+		name string
+		key  string
+		want *Principal
+		err  error
+	}{
+		{name: "no key", err: ErrNoCredentials},
+		{name: "no prefix", key: "0123456789abcdef", err: ErrInvalidCredentials},
+		{name: "unknown", key: KeyPrefix + "fedcba9876543210", err: ErrInvalidCredentials},
+		// Only the hash is stored, the key itself isn't found
+		{name: "the hash as key", key: KeyPrefix + HashKey(key), err: ErrInvalidCredentials},
+		{name: "known", key: key, want: &Principal{Subject: "key:7", Name: "door", Method: MethodAPIKey, Role: Staff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.key != "" {
+				h.Set(HeaderAPIKey, tt.key)
+			}
+
+			p, err := APIKeys{DB: db}.Authenticate(context.Background(), h)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.err)
+			}
+			if tt.want != nil && (p == nil || *p != *tt.want) {
+				t.Errorf("Authenticate() = %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupKeyFailure(t *testing.T) {
+	db := keysTable{}.open(t)
+	db.Close()
+
+	_, err := APIKeys{DB: db}.Authenticate(context.Background(), http.Header{HeaderAPIKey: {KeyPrefix + "x"}})
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() = %v, want the error of the database", err)
+	}
+}
+
+// keysTable is an api_keys table by hash, the statements of LookupKey are
+// answered with the row of their argument
+type keysTable map[string]keyRow
+
+type keyRow struct {
+	id         int64
+	name, role string
+}
+
+func (k keysTable) open(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := sql.OpenDB(k)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func (k keysTable) Connect(context.Context) (driver.Conn, error) { return keysConn{k}, nil }
+func (k keysTable) Driver() driver.Driver                        { return nil }
+
+type keysConn struct{ keys keysTable }
+
+func (c keysConn) Prepare(q string) (driver.Stmt, error) {
+	if !strings.Contains(q, "FROM api_keys WHERE key_hash") {
+		return nil, errors.New("unexpected statement: " + q)
+	}
+	return keysStmt(c), nil
+}
+func (keysConn) Close() error              { return nil }
+func (keysConn) Begin() (driver.Tx, error) { return nil, errors.New("no transactions") }
+
+type keysStmt keysConn
+
+func (keysStmt) Close() error  { return nil }
+func (keysStmt) NumInput() int { return 1 }
+func (keysStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("read only")
+}
+
+func (s keysStmt) Query(args []driver.Value) (driver.Rows, error) {
+	hash, _ := args[0].(string)
+	row, ok := s.keys[hash]
+	return &keysRows{row: row, done: !ok}, nil
+}
+
+type keysRows struct {
+	row  keyRow
+	done bool
+}
+
+func (*keysRows) Columns() []string { return []string{"id", "name", "role"} }
+func (*keysRows) Close() error      { return nil }
+
+func (r *keysRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0], dest[1], dest[2] = r.row.id, r.row.name, r.row.role
+	return nil
+}
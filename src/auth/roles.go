@@ -0,0 +1,65 @@
+package auth
+
+// Role of a principal, from the role column of the API key or the role
+// claim of the token
+type Role string
+
+const (
+	Viewer    Role = "viewer"    // Reads everything
+	Staff     Role = "staff"     // Also registers participants for events, e.g. at the door
+	Organizer Role = "organizer" // Also manages events, their tickets and participants, only its own events
+	Admin     Role = "admin"     // Everything, e.g. building the database
+)
+
+// Permission required by a route, declared in the routers
+type Permission string
+
+const (
+	Read               Permission = "read"
+	RegisterTickets    Permission = "tickets:register"
+	ManageTickets      Permission = "tickets:manage"
+	CreateEvents       Permission = "events:create"
+	ManageEvents       Permission = "events:manage"
+	CreateParticipants Permission = "participants:create"
+	ManageParticipants Permission = "participants:manage"
+	Administer         Permission = "admin" // Routes that touch every event at once
+)
+
+var permissions = map[Role][]Permission{
+	Viewer:    {Read},
+	Staff:     {Read, RegisterTickets},
+	Organizer: {Read, RegisterTickets, ManageTickets, CreateEvents, ManageEvents, CreateParticipants, ManageParticipants},
+	Admin:     {Read, RegisterTickets, ManageTickets, CreateEvents, ManageEvents, CreateParticipants, ManageParticipants, Administer},
+}
+
+// Roles in order, each one can do what the previous ones can
+var Roles = []Role{Viewer, Staff, Organizer, Admin}
+
+// ParseRole of a key or a token, an empty one is a viewer
+func ParseRole(s string) (Role, bool) {
+	if s == "" {
+		return Viewer, true
+	}
+	_, ok := permissions[Role(s)]
+	return Role(s), ok
+}
+
+// Can tells if the role has the permission
+func (r Role) Can(permission Permission) bool {
+	for _, p := range permissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Scoped roles only manage the events they organize, see Organizes
+func (r Role) Scoped() bool {
+	return r == Organizer
+}
+
+// Can tells if the principal has the permission, false without principal
+func (p *Principal) Can(permission Permission) bool {
+	return p != nil && p.Role.Can(permission)
+}
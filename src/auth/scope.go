@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// The organizers of each event are in event_organizers, an organizer is
+// added to the events it creates
+
+// AssignEvent makes the subject one of the organizers of the event
+func AssignEvent(ctx context.Context, db database.Preparer, event int64, subject string) error {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "INSERT INTO event_organizers(event, subject) VALUES($1, $2) ON CONFLICT DO NOTHING;"
+	case storage.MySQL:
+		q = "INSERT IGNORE INTO event_organizers(event, subject) VALUES(?, ?);"
+	}
+
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, event, subject)
+	return err
+}
+
+// OrganizesEvent tells if the subject organizes the event, true if there's
+// no such event so that the handler responds with 404
+func OrganizesEvent(ctx context.Context, db database.Connecter, subject string, event int64) (bool, error) {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "SELECT NOT EXISTS (SELECT 1 FROM events WHERE id = $1 AND id NOT IN (SELECT event FROM event_organizers WHERE subject = $2));"
+	case storage.MySQL:
+		q = "SELECT NOT EXISTS (SELECT 1 FROM events WHERE id = ? AND id NOT IN (SELECT event FROM event_organizers WHERE subject = ?));"
+	}
+	return organizes(ctx, db, q, event, subject)
+}
+
+// OrganizesTicket tells if the subject organizes the event of the ticket,
+// true if there's no such ticket
+func OrganizesTicket(ctx context.Context, db database.Connecter, subject string, ticket int64) (bool, error) {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "SELECT NOT EXISTS (SELECT 1 FROM tickets WHERE id = $1 AND event NOT IN (SELECT event FROM event_organizers WHERE subject = $2));"
+	case storage.MySQL:
+		q = "SELECT NOT EXISTS (SELECT 1 FROM tickets WHERE id = ? AND event NOT IN (SELECT event FROM event_organizers WHERE subject = ?));"
+	}
+	return organizes(ctx, db, q, ticket, subject)
+}
+
+// OrganizesParticipant tells if the subject organizes every event the
+// participant has a ticket for, false if it has none, nobody organizes it
+// yet. True if there's no such participant.
+func OrganizesParticipant(ctx context.Context, db database.Connecter, subject string, participant int64) (bool, error) {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "SELECT NOT EXISTS (SELECT 1 FROM participants AS p WHERE p.id = $1 AND (NOT EXISTS (SELECT 1 FROM tickets WHERE participant = p.id) OR EXISTS (SELECT 1 FROM tickets WHERE participant = p.id AND event NOT IN (SELECT event FROM event_organizers WHERE subject = $2))));"
+	case storage.MySQL:
+		q = "SELECT NOT EXISTS (SELECT 1 FROM participants AS p WHERE p.id = ? AND (NOT EXISTS (SELECT 1 FROM tickets WHERE participant = p.id) OR EXISTS (SELECT 1 FROM tickets WHERE participant = p.id AND event NOT IN (SELECT event FROM event_organizers WHERE subject = ?))));"
+	}
+	return organizes(ctx, db, q, participant, subject)
+}
+
+func organizes(ctx context.Context, db database.Connecter, q string, id int64, subject string) (bool, error) {
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	var ok bool
+	err = stmt.QueryRowContext(ctx, id, subject).Scan(&ok)
+	return ok, err
+}
@@ -0,0 +1,277 @@
+// Package client calls the API over HTTP with the types of the models
+// package, e.g.
+//
+//	c, err := client.New("http://127.0.0.1:8000")
+//	event, err := c.Events.Get(ctx, 3)
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+const (
+	mimeJSON       = "application/json"
+	mimeMergePatch = "application/merge-patch+json"
+	userAgent      = "restapi-technical-test-client"
+)
+
+const (
+	DefaultRetries = 3
+	DefaultBackoff = time.Millisecond * 250
+	MaxBackoff     = time.Second * 10
+)
+
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	header  http.Header
+	retries int
+	backoff time.Duration
+
+	Events       *EventsService
+	Participants *ParticipantsService
+	Tickets      *TicketsService
+	Persistence  *PersistenceService
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set a timeout
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.http = h }
+}
+
+// WithRetries sets how many times a failed request is sent again, the
+// first wait is backoff and it doubles each time
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) { c.retries, c.backoff = retries, backoff }
+}
+
+// WithHeader is sent in every request, e.g. the credentials
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Set(key, value) }
+}
+
+// New creates a client of the API served at baseURL, e.g. http://127.0.0.1:8000
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var c = &Client{
+		baseURL: u,
+		http:    http.DefaultClient,
+		header:  make(http.Header),
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.Events = &EventsService{c}
+	c.Participants = &ParticipantsService{c}
+	c.Tickets = &TicketsService{c}
+	c.Persistence = &PersistenceService{c}
+	return c, nil
+}
+
+// The successful envelope, data is decoded by the caller
+type envelope struct {
+	Data       json.RawMessage    `json:"data"`
+	Pagination *models.Pagination `json:"pagination"`
+}
+
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	contentType string // JSON unless set
+	body        interface{}
+}
+
+// do sends the request and decodes the data of the response into out,
+// the errors of the API are returned as *Error
+func (c *Client) do(ctx context.Context, r request, out interface{}) (*http.Response, *models.Pagination, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var u = *c.baseURL
+	u.Path += r.path
+	u.RawQuery = r.query.Encode()
+
+	// POST is only sent again with an Idempotency-Key, the API answers
+	// the retries with the first response
+	if r.method == http.MethodPost && r.header.Get("Idempotency-Key") == "" {
+		if r.header == nil {
+			r.header = make(http.Header)
+		}
+		r.header.Set("Idempotency-Key", newKey())
+	}
+
+	var (
+		res *http.Response
+		err error
+	)
+
+	for attempt := 0; ; attempt++ {
+		res, err = c.send(ctx, r, u.String(), body)
+		if attempt >= c.retries || !retryable(r.method, res, err) {
+			break
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+
+		if err = sleep(ctx, c.wait(attempt, res)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return res, nil, decodeError(res)
+	}
+	if out == nil || res.StatusCode == http.StatusNoContent || res.StatusCode == http.StatusNotModified {
+		return res, nil, nil
+	}
+
+	var env envelope
+	if err = json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return res, nil, err
+	}
+	if len(env.Data) > 0 {
+		err = json.Unmarshal(env.Data, out)
+	}
+	return res, env.Pagination, err
+}
+
+func (c *Client) send(ctx context.Context, r request, u string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, r.method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+	// The legacy envelope of the errors, see BadResponse
+	req.Header.Set("Accept", mimeJSON)
+	req.Header.Set("User-Agent", userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", mimeJSON)
+		if r.contentType != "" {
+			req.Header.Set("Content-Type", r.contentType)
+		}
+	}
+
+	return c.http.Do(req)
+}
+
+// Network errors and the statuses of an overloaded or restarting server,
+// PATCH isn't idempotent
+func retryable(method string, res *http.Response, err error) bool {
+	if method == http.MethodPatch {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Exponential backoff with jitter, Retry-After wins when the server sends it
+func (c *Client) wait(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && s >= 0 {
+			return time.Duration(s) * time.Second
+		}
+	}
+
+	d := time.Duration(float64(c.backoff) * math.Pow(2, float64(attempt)))
+	if d > MaxBackoff || d <= 0 {
+		d = MaxBackoff
+	}
+	return d/2 + time.Duration(mrand.Int63n(int64(d/2)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func newKey() string {
+	var b = make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ETag of a version, sent as If-Match
+func ifMatch(version uint32) http.Header {
+	var h = make(http.Header)
+	if version != 0 {
+		h.Set("If-Match", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+	}
+	return h
+}
+
+// Version in the ETag of the response, def if there is none
+func etagVersion(res *http.Response, def uint32) uint32 {
+	v, err := strconv.ParseUint(strings.Trim(res.Header.Get("ETag"), `"`), 10, 32)
+	if err != nil {
+		return def
+	}
+	return uint32(v)
+}
+
+func pagination(page *models.Pagination) models.Pagination {
+	if page == nil {
+		return models.Pagination{}
+	}
+	return *page
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// attempt is a request as the server received it
+type attempt struct {
+	method string
+	key    string // Idempotency-Key
+	body   string
+}
+
+// server answers the requests with the statuses in order, the last one
+// once they run out
+type server struct {
+	statuses   []int
+	retryAfter string
+
+	mu       sync.Mutex
+	attempts []attempt
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.attempts = append(s.attempts, attempt{r.Method, r.Header.Get("Idempotency-Key"), string(body)})
+	status := s.statuses[len(s.statuses)-1]
+	if n := len(s.attempts); n <= len(s.statuses) {
+		status = s.statuses[n-1]
+	}
+	s.mu.Unlock()
+
+	if status >= http.StatusBadRequest {
+		if s.retryAfter != "" {
+			w.Header().Set("Retry-After", s.retryAfter)
+		}
+		w.Header().Set("Content-Type", mimeJSON)
+		w.WriteHeader(status)
+		io.WriteString(w, `{"method": "test", "errors": [{"message": "Try again"}]}`)
+		return
+	}
+
+	w.Header().Set("Content-Type", mimeJSON)
+	w.WriteHeader(status)
+	io.WriteString(w, `{"data": {"name": "Fest"}}`)
+}
+
+func newClient(t *testing.T, s *server) *Client {
+	t.Helper()
+
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	c, err := New(ts.URL, WithRetries(DefaultRetries, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		key        string // Idempotency-Key of the caller
+		statuses   []int
+		retryAfter string
+		attempts   int
+		status     int // of the error, 0 if none
+	}{
+		{name: "no retry on success", method: http.MethodGet, statuses: []int{200}, attempts: 1},
+		{name: "GET on 503", method: http.MethodGet, statuses: []int{503, 503, 200}, attempts: 3},
+		{name: "GET on 429 with Retry-After", method: http.MethodGet, statuses: []int{429, 200}, retryAfter: "0", attempts: 2},
+		{name: "PUT on 502", method: http.MethodPut, statuses: []int{502, 200}, attempts: 2},
+		{name: "DELETE on 504", method: http.MethodDelete, statuses: []int{504, 204}, attempts: 2},
+		{name: "retries run out", method: http.MethodGet, statuses: []int{503}, attempts: DefaultRetries + 1, status: 503},
+		{name: "no retry on 500", method: http.MethodGet, statuses: []int{500, 200}, attempts: 1, status: 500},
+		{name: "no retry on 404", method: http.MethodGet, statuses: []int{404, 200}, attempts: 1, status: 404},
+		{name: "no retry on PATCH", method: http.MethodPatch, statuses: []int{503, 200}, attempts: 1, status: 503},
+		{name: "no retry on PATCH with Retry-After", method: http.MethodPatch, statuses: []int{429, 200}, retryAfter: "0", attempts: 1, status: 429},
+		{name: "POST on 503", method: http.MethodPost, statuses: []int{503, 503, 201}, attempts: 3},
+		{name: "POST with the key of the caller", method: http.MethodPost, key: "first-order", statuses: []int{503, 201}, attempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{statuses: tt.statuses, retryAfter: tt.retryAfter}
+			c := newClient(t, s)
+
+			r := request{method: tt.method, path: "/api/v1/event", body: map[string]string{"name": "Fest"}}
+			if tt.key != "" {
+				r.header = http.Header{"Idempotency-Key": {tt.key}}
+			}
+
+			var out struct{ Name string }
+			_, _, err := c.do(context.Background(), r, &out)
+
+			var e *Error
+			switch {
+			case tt.status == 0 && err != nil:
+				t.Fatalf("do() = %v", err)
+			case tt.status == 0 && tt.statuses[len(tt.statuses)-1] != http.StatusNoContent && out.Name != "Fest":
+				t.Errorf("out = %+v, want the data of the last response", out)
+			case tt.status != 0 && (!errors.As(err, &e) || e.StatusCode != tt.status):
+				t.Errorf("do() = %v, want a %d", err, tt.status)
+			}
+
+			if len(s.attempts) != tt.attempts {
+				t.Fatalf("%d attempts, want %d", len(s.attempts), tt.attempts)
+			}
+			for i, a := range s.attempts {
+				if a.body != `{"name":"Fest"}` {
+					t.Errorf("attempt %d sent %q", i, a.body)
+				}
+			}
+
+			// Every POST is sent with a key, the same one in its retries
+			first := s.attempts[0].key
+			switch {
+			case tt.method == http.MethodPost && tt.key != "" && first != tt.key:
+				t.Errorf("Idempotency-Key = %q, want the one of the caller %q", first, tt.key)
+			case tt.method == http.MethodPost && first == "":
+				t.Error("The POST was sent without an Idempotency-Key")
+			case tt.method != http.MethodPost && first != "":
+				t.Errorf("The %s was sent with the Idempotency-Key %q", tt.method, first)
+			}
+			for i, a := range s.attempts {
+				if a.key != first {
+					t.Errorf("attempt %d has the Idempotency-Key %q, want %q", i, a.key, first)
+				}
+			}
+		})
+	}
+}
+
+func TestDoNewKeys(t *testing.T) {
+	s := &server{statuses: []int{201}}
+	c := newClient(t, s)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.do(context.Background(), request{method: http.MethodPost, path: "/api/v1/event"}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.attempts[0].key == s.attempts[1].key {
+		t.Errorf("Two POSTs were sent with the same Idempotency-Key %q", s.attempts[0].key)
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	t.Run("canceled during the wait", func(t *testing.T) {
+		s := &server{statuses: []int{503}, retryAfter: "60"}
+		c := newClient(t, s)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		began := time.Now()
+		_, _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/event/1"}, nil)
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("do() = %v, want the deadline of the context", err)
+		}
+		if elapsed := time.Since(began); elapsed > 5*time.Second {
+			t.Errorf("do() returned after %s, the Retry-After outlived the context", elapsed)
+		}
+		if len(s.attempts) != 1 {
+			t.Errorf("%d attempts, want 1", len(s.attempts))
+		}
+	})
+
+	t.Run("canceled before", func(t *testing.T) {
+		s := &server{statuses: []int{200}}
+		c := newClient(t, s)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, _, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/event/1"}, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("do() = %v, want context.Canceled", err)
+		}
+		if len(s.attempts) != 0 {
+			t.Errorf("%d attempts, want none", len(s.attempts))
+		}
+	})
+}
+
+func TestRetryable(t *testing.T) {
+	var failed = errors.New("connection reset")
+
+	tests := []struct {
+		method string
+		status int // 0 with the error
+		want   bool
+	}{
+		{method: http.MethodGet, want: true},
+		{method: http.MethodPost, want: true},
+		{method: http.MethodPatch, want: false},
+		{method: http.MethodGet, status: 200, want: false},
+		{method: http.MethodGet, status: 400, want: false},
+		{method: http.MethodGet, status: 409, want: false},
+		{method: http.MethodGet, status: 429, want: true},
+		{method: http.MethodGet, status: 500, want: false},
+		{method: http.MethodGet, status: 502, want: true},
+		{method: http.MethodGet, status: 503, want: true},
+		{method: http.MethodGet, status: 504, want: true},
+		{method: http.MethodPatch, status: 503, want: false},
+	}
+
+	for _, tt := range tests {
+		var (
+			res *http.Response
+			err error
+		)
+		if tt.status == 0 {
+			err = failed
+		} else {
+			res = &http.Response{StatusCode: tt.status}
+		}
+
+		if got := retryable(tt.method, res, err); got != tt.want {
+			t.Errorf("retryable(%s, %d) = %v, want %v", tt.method, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	c := &Client{backoff: 100 * time.Millisecond}
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string // none if empty
+		min, max   time.Duration
+	}{
+		{name: "Retry-After", retryAfter: "2", min: 2 * time.Second, max: 2 * time.Second},
+		{name: "Retry-After over the backoff", attempt: 5, retryAfter: "0", min: 0, max: 0},
+		{name: "first", min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "third", attempt: 2, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "capped", attempt: 20, min: MaxBackoff / 2, max: MaxBackoff},
+		{name: "overflow", attempt: 1000, min: MaxBackoff / 2, max: MaxBackoff},
+		{name: "negative Retry-After", retryAfter: "-1", min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header)}
+			if tt.retryAfter != "" {
+				res.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			// The jitter is random, a few draws
+			for i := 0; i < 20; i++ {
+				if d := c.wait(tt.attempt, res); d < tt.min || d > tt.max {
+					t.Fatalf("wait(%d) = %s, want between %s and %s", tt.attempt, d, tt.min, tt.max)
+				}
+			}
+		})
+	}
+
+	if d := c.wait(0, nil); d < 50*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("wait() = %s without a response, want the backoff", d)
+	}
+}
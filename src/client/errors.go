@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// Error is an error response of the API, decoded from models.BadResponse
+type Error struct {
+	StatusCode int
+	Route      string // Name of the route, e.g. events.get
+	Message    string
+	Fields     []models.FieldError
+	Response   models.BadResponse
+}
+
+func (e *Error) Error() string {
+	var msg = fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	for _, f := range e.Fields {
+		msg += "; " + f.Field + " " + f.Message
+	}
+	return msg
+}
+
+// The first item of the errors is the detail, the others the fields
+func decodeError(res *http.Response) error {
+	var e = &Error{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil || json.Unmarshal(body, &e.Response) != nil {
+		return e
+	}
+
+	e.Route = e.Response.Method
+	for i, item := range e.Response.Errors {
+		reason, _ := item["reason"].(string)
+		message, _ := item["message"].(string)
+
+		if i == 0 {
+			if message != "" {
+				e.Message = message
+			}
+			continue
+		}
+		e.Fields = append(e.Fields, models.FieldError{Field: reason, Message: message})
+	}
+	return e
+}
+
+func hasStatus(err error, status int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == status
+}
+
+func IsNotFound(err error) bool { return hasStatus(err, http.StatusNotFound) }
+func IsConflict(err error) bool { return hasStatus(err, http.StatusConflict) }
+func IsInvalid(err error) bool  { return hasStatus(err, http.StatusUnprocessableEntity) }
+
+// IsPreconditionFailed reports whether the resource was modified since
+// the version sent as If-Match
+func IsPreconditionFailed(err error) bool { return hasStatus(err, http.StatusPreconditionFailed) }
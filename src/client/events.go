@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+type EventsService struct {
+	c *Client
+}
+
+// List requests a page of events
+func (s *EventsService) List(ctx context.Context, opts ListOptions) ([]models.Event, models.Pagination, error) {
+	var events []models.Event
+	_, page, err := s.c.do(ctx, request{method: http.MethodGet, path: "/api/v1/events", query: opts.query()}, &events)
+	return events, pagination(page), err
+}
+
+// Iter goes through all the events from opts on, a page at a time
+func (s *EventsService) Iter(ctx context.Context, opts ListOptions) *EventIterator {
+	var it = new(EventIterator)
+	it.pager = newPager(ctx, opts, func(ctx context.Context, opts ListOptions) (n int, page models.Pagination, err error) {
+		it.page, page, err = s.List(ctx, opts)
+		return len(it.page), page, err
+	})
+	return it
+}
+
+func (s *EventsService) Get(ctx context.Context, id int) (models.Event, error) {
+	var event models.Event
+	_, _, err := s.c.do(ctx, request{method: http.MethodGet, path: "/api/v1/event/" + itoa(id)}, &event)
+	return event, err
+}
+
+func (s *EventsService) Create(ctx context.Context, event models.Event) (models.Event, error) {
+	var created models.Event
+	_, _, err := s.c.do(ctx, request{method: http.MethodPost, path: "/api/v1/event", body: event}, &created)
+	return created, err
+}
+
+// Update replaces the event e.Id, e.Version is sent as If-Match
+// unless it's zero. The API doesn't send the event back, it's returned
+// with the new version.
+func (s *EventsService) Update(ctx context.Context, e models.Event) (models.Event, error) {
+	res, _, err := s.c.do(ctx, request{
+		method: http.MethodPut,
+		path:   "/api/v1/event/" + itoa(int(e.Id)),
+		header: ifMatch(e.Version),
+		body:   e,
+	}, nil)
+	if err != nil {
+		return e, err
+	}
+
+	e.Version = etagVersion(res, e.Version+1)
+	return e, nil
+}
+
+// Modify applies a JSON Merge Patch (RFC 7396), e.g. map[string]interface{}{"name": "A beer"},
+// version is sent as If-Match unless it's zero
+func (s *EventsService) Modify(ctx context.Context, id int, patch interface{}, version uint32) (models.Event, error) {
+	var event models.Event
+	_, _, err := s.c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/api/v1/event/" + itoa(id),
+		header:      ifMatch(version),
+		contentType: mimeMergePatch,
+		body:        patch,
+	}, &event)
+	return event, err
+}
+
+// Delete removes the event, version is sent as If-Match unless it's zero
+func (s *EventsService) Delete(ctx context.Context, id int, version uint32) error {
+	_, _, err := s.c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/event/" + itoa(id), header: ifMatch(version)}, nil)
+	return err
+}
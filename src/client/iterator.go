@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// ListOptions of the collection routes, the zero value is the first page
+// with the limit of the server
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Desc   bool
+}
+
+func (o ListOptions) query() url.Values {
+	var q = make(url.Values)
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Desc {
+		q.Set("desc", "true")
+	}
+	return q
+}
+
+// pager requests the pages of a collection one after the other, fetch
+// decodes a page and returns how many items it had
+type pager struct {
+	ctx   context.Context
+	opts  ListOptions
+	fetch func(ctx context.Context, opts ListOptions) (int, models.Pagination, error)
+	index int
+	size  int
+	done  bool
+	err   error
+}
+
+// next moves to the next item, requesting the next page when needed
+func (p *pager) next() bool {
+	if p.err != nil {
+		return false
+	}
+
+	p.index++
+	if p.index < p.size {
+		return true
+	}
+	if p.done {
+		return false
+	}
+
+	n, page, err := p.fetch(p.ctx, p.opts)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.index, p.size = 0, n
+	p.opts.Offset += n
+	p.done = n == 0 || p.opts.Offset >= page.Total
+
+	return n > 0
+}
+
+func newPager(ctx context.Context, opts ListOptions, fetch func(context.Context, ListOptions) (int, models.Pagination, error)) pager {
+	return pager{ctx: ctx, opts: opts, fetch: fetch, index: -1}
+}
+
+// EventIterator goes through a collection of events, e.g.
+//
+//	it := c.Events.Iter(ctx, client.ListOptions{})
+//	for it.Next() {
+//		fmt.Println(it.Event().Name)
+//	}
+//	if err := it.Err(); err != nil {
+type EventIterator struct {
+	pager
+	page []models.Event
+}
+
+func (it *EventIterator) Next() bool          { return it.next() }
+func (it *EventIterator) Event() models.Event { return it.page[it.index] }
+func (it *EventIterator) Err() error          { return it.err }
+
+type ParticipantIterator struct {
+	pager
+	page []models.Participant
+}
+
+func (it *ParticipantIterator) Next() bool                      { return it.next() }
+func (it *ParticipantIterator) Participant() models.Participant { return it.page[it.index] }
+func (it *ParticipantIterator) Err() error                      { return it.err }
+
+// TicketIterator goes through tickets with the names of their participant
+// and event
+type TicketIterator struct {
+	pager
+	page []models.TicketView
+}
+
+func (it *TicketIterator) Next() bool                { return it.next() }
+func (it *TicketIterator) Ticket() models.TicketView { return it.page[it.index] }
+func (it *TicketIterator) Err() error                { return it.err }
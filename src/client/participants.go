@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+type ParticipantsService struct {
+	c *Client
+}
+
+// List requests a page of participants
+func (s *ParticipantsService) List(ctx context.Context, opts ListOptions) ([]models.Participant, models.Pagination, error) {
+	var participants []models.Participant
+	_, page, err := s.c.do(ctx, request{method: http.MethodGet, path: "/api/v1/participants", query: opts.query()}, &participants)
+	return participants, pagination(page), err
+}
+
+// Iter goes through all the participants from opts on, a page at a time
+func (s *ParticipantsService) Iter(ctx context.Context, opts ListOptions) *ParticipantIterator {
+	var it = new(ParticipantIterator)
+	it.pager = newPager(ctx, opts, func(ctx context.Context, opts ListOptions) (n int, page models.Pagination, err error) {
+		it.page, page, err = s.List(ctx, opts)
+		return len(it.page), page, err
+	})
+	return it
+}
+
+func (s *ParticipantsService) Get(ctx context.Context, id int) (models.Participant, error) {
+	var participant models.Participant
+	_, _, err := s.c.do(ctx, request{method: http.MethodGet, path: "/api/v1/participant/" + itoa(id)}, &participant)
+	return participant, err
+}
+
+func (s *ParticipantsService) Create(ctx context.Context, participant models.Participant) (models.Participant, error) {
+	var created models.Participant
+	_, _, err := s.c.do(ctx, request{method: http.MethodPost, path: "/api/v1/participant", body: participant}, &created)
+	return created, err
+}
+
+// Update replaces the participant p.Id, p.Version is sent as If-Match
+// unless it's zero. The API doesn't send the participant back, it's returned
+// with the new version.
+func (s *ParticipantsService) Update(ctx context.Context, p models.Participant) (models.Participant, error) {
+	res, _, err := s.c.do(ctx, request{
+		method: http.MethodPut,
+		path:   "/api/v1/participant/" + itoa(int(p.Id)),
+		header: ifMatch(p.Version),
+		body:   p,
+	}, nil)
+	if err != nil {
+		return p, err
+	}
+
+	p.Version = etagVersion(res, p.Version+1)
+	return p, nil
+}
+
+// Modify applies a JSON Merge Patch (RFC 7396), e.g. map[string]interface{}{"name": "A beer"},
+// version is sent as If-Match unless it's zero
+func (s *ParticipantsService) Modify(ctx context.Context, id int, patch interface{}, version uint32) (models.Participant, error) {
+	var participant models.Participant
+	_, _, err := s.c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/api/v1/participant/" + itoa(id),
+		header:      ifMatch(version),
+		contentType: mimeMergePatch,
+		body:        patch,
+	}, &participant)
+	return participant, err
+}
+
+// Delete removes the participant, version is sent as If-Match unless it's zero
+func (s *ParticipantsService) Delete(ctx context.Context, id int, version uint32) error {
+	_, _, err := s.c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/participant/" + itoa(id), header: ifMatch(version)}, nil)
+	return err
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+type PersistenceService struct {
+	c *Client
+}
+
+// Build creates the schemas and the sample data in the database of the
+// DSN, persistence is psql or mysql. The API answers in plain text, the
+// message is returned as it is. It's never retried.
+func (s *PersistenceService) Build(ctx context.Context, persistence string, dsn models.DSN) (string, error) {
+	body, err := json.Marshal(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	var u = *s.c.baseURL
+	u.Path += "/persistence/build/" + persistence
+
+	res, err := s.c.send(ctx, request{method: http.MethodPost}, u.String(), body)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	msg, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return "", &Error{StatusCode: res.StatusCode, Route: "persistence.build", Message: strings.TrimSpace(string(msg))}
+	}
+	return string(msg), nil
+}
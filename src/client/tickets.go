@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+type TicketsService struct {
+	c *Client
+}
+
+// List requests a page of tickets, with the names of their participant
+// and event
+func (s *TicketsService) List(ctx context.Context, opts ListOptions) ([]models.TicketView, models.Pagination, error) {
+	return s.listAt(ctx, "/api/v1/tickets", opts)
+}
+
+// Iter goes through all the tickets from opts on, a page at a time
+func (s *TicketsService) Iter(ctx context.Context, opts ListOptions) *TicketIterator {
+	return s.iter(ctx, "/api/v1/tickets", opts)
+}
+
+// ListByEvent requests a page of the tickets of an event
+func (s *TicketsService) ListByEvent(ctx context.Context, event int, opts ListOptions) ([]models.TicketView, models.Pagination, error) {
+	return s.listAt(ctx, "/api/v1/event/"+itoa(event)+"/tickets", opts)
+}
+
+// ListByParticipant requests a page of the tickets of a participant
+func (s *TicketsService) ListByParticipant(ctx context.Context, participant int, opts ListOptions) ([]models.TicketView, models.Pagination, error) {
+	return s.listAt(ctx, "/api/v1/participant/"+itoa(participant)+"/tickets", opts)
+}
+
+// IterByEvent goes through the tickets of an event
+func (s *TicketsService) IterByEvent(ctx context.Context, event int, opts ListOptions) *TicketIterator {
+	return s.iter(ctx, "/api/v1/event/"+itoa(event)+"/tickets", opts)
+}
+
+// IterByParticipant goes through the tickets of a participant
+func (s *TicketsService) IterByParticipant(ctx context.Context, participant int, opts ListOptions) *TicketIterator {
+	return s.iter(ctx, "/api/v1/participant/"+itoa(participant)+"/tickets", opts)
+}
+
+func (s *TicketsService) listAt(ctx context.Context, path string, opts ListOptions) ([]models.TicketView, models.Pagination, error) {
+	var tickets []models.TicketView
+	_, page, err := s.c.do(ctx, request{method: http.MethodGet, path: path, query: opts.query()}, &tickets)
+	return tickets, pagination(page), err
+}
+
+func (s *TicketsService) iter(ctx context.Context, path string, opts ListOptions) *TicketIterator {
+	var it = new(TicketIterator)
+	it.pager = newPager(ctx, opts, func(ctx context.Context, opts ListOptions) (n int, page models.Pagination, err error) {
+		it.page, page, err = s.listAt(ctx, path, opts)
+		return len(it.page), page, err
+	})
+	return it
+}
+
+func (s *TicketsService) Get(ctx context.Context, id int) (models.TicketView, error) {
+	var ticket models.TicketView
+	_, _, err := s.c.do(ctx, request{method: http.MethodGet, path: "/api/v1/ticket/" + itoa(id)}, &ticket)
+	return ticket, err
+}
+
+// Find gets the ticket of a participant for an event, see IsNotFound
+// when there is none
+func (s *TicketsService) Find(ctx context.Context, event, participant int) (models.TicketView, error) {
+	var ticket models.TicketView
+	_, _, err := s.c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/event/" + itoa(event) + "/participant/" + itoa(participant),
+	}, &ticket)
+	return ticket, err
+}
+
+// Create registers a participant for an event, see IsConflict when it
+// already has a ticket for it
+func (s *TicketsService) Create(ctx context.Context, participant, event int) (models.Ticket, error) {
+	var ticket models.Ticket
+	_, _, err := s.c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/ticket",
+		body:   models.Ticket{Participant: uint64(participant), Event: uint16(event)},
+	}, &ticket)
+	return ticket, err
+}
+
+// Delete removes the ticket, version is sent as If-Match unless it's zero
+func (s *TicketsService) Delete(ctx context.Context, id int, version uint32) error {
+	_, _, err := s.c.do(ctx, request{method: http.MethodDelete, path: "/api/v1/ticket/" + itoa(id), header: ifMatch(version)}, nil)
+	return err
+}
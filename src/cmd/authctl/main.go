@@ -0,0 +1,149 @@
+// authctl manages the credentials of the API on the server side, with the
+// same environment as the server (dsn, PERSISTENCE_NAME, JWT_*), e.g.
+//
+//	authctl create-key -name door -role staff
+//	authctl revoke-key -id 3
+//	authctl token -sub alice -role organizer -ttl 1h
+//	authctl assign-event -event 7 -sub alice
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+const usage = `Usage: authctl <command> [flags]
+
+Commands:
+  create-key    Store a new API key and print it, it can't be retrieved later
+  revoke-key    Stop accepting an API key
+  token         Print an HS256 bearer token signed with JWT_SECRET
+  assign-event  Make a subject one of the organizers of an event
+
+Roles: viewer, staff, organizer and admin, each one can do what the previous
+ones can. Organizers only manage the events they created or were assigned.
+
+Run 'authctl <command> -h' for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	var (
+		fs  = flag.NewFlagSet("authctl "+args[0], flag.ContinueOnError)
+		err error
+	)
+
+	switch args[0] {
+	case "create-key":
+		var (
+			name = fs.String("name", "", "Name of the key, e.g. the client using it")
+			role = fs.String("role", string(auth.Viewer), "Role of the key")
+		)
+		if fs.Parse(args[1:]) != nil || *name == "" || len(*name) > 50 || !validRole(*role) {
+			fs.Usage()
+			return 2
+		}
+		err = withDB(func(ctx context.Context, db database.Connecter) error {
+			id, key, err := auth.CreateKey(ctx, db, *name, auth.Role(*role))
+			if err == nil {
+				fmt.Printf("%d\t%s\n", id, key)
+			}
+			return err
+		})
+
+	case "revoke-key":
+		id := fs.Int64("id", 0, "ID of the key")
+		if fs.Parse(args[1:]) != nil || *id < 1 {
+			fs.Usage()
+			return 2
+		}
+		err = withDB(func(ctx context.Context, db database.Connecter) error {
+			revoked, err := auth.RevokeKey(ctx, db, *id)
+			if err == nil && !revoked {
+				err = fmt.Errorf("there's no API key %d or it was already revoked", *id)
+			}
+			return err
+		})
+
+	case "token":
+		var (
+			sub  = fs.String("sub", "", "Subject of the token")
+			name = fs.String("name", "", "Name of the subject")
+			role = fs.String("role", string(auth.Viewer), "Role of the subject")
+			ttl  = fs.Duration("ttl", time.Hour, "Time until the token expires")
+		)
+		if fs.Parse(args[1:]) != nil || *sub == "" || *ttl <= 0 || !validRole(*role) {
+			fs.Usage()
+			return 2
+		}
+
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			fmt.Fprintln(os.Stderr, "Error: JWT_SECRET is not set")
+			return 1
+		}
+
+		var token string
+		token, err = auth.SignHS256([]byte(secret), *sub, *name, auth.Role(*role), os.Getenv("JWT_ISSUER"), os.Getenv("JWT_AUDIENCE"), *ttl)
+		if err == nil {
+			fmt.Println(token)
+		}
+
+	case "assign-event":
+		var (
+			event = fs.Int64("event", 0, "ID of the event")
+			sub   = fs.String("sub", "", "Subject of the organizer, the sub claim of its tokens or key:<ID> for an API key")
+		)
+		if fs.Parse(args[1:]) != nil || *event < 1 || *sub == "" {
+			fs.Usage()
+			return 2
+		}
+		err = withDB(func(ctx context.Context, db database.Connecter) error {
+			return auth.AssignEvent(ctx, db, *event, *sub)
+		})
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+func validRole(role string) bool {
+	_, ok := auth.ParseRole(role)
+	return ok && role != ""
+}
+
+func withDB(call func(ctx context.Context, db database.Connecter) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	db := storage.Get(constants.Persistence)
+	if err := db.Connect(ctx); err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+	defer db.Close()
+
+	return call(ctx, db)
+}
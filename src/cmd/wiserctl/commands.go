@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/luisnquin/restapi-technical-test/src/client"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+var eventCommands = map[string]command{
+	"list": {"List the events", func(fs *flag.FlagSet) func(env, []string) error {
+		var l = listFlags(fs)
+		return func(e env, args []string) error {
+			if l.all {
+				var (
+					all []models.Event
+					it  = e.client.Events.Iter(e.ctx, l.opts)
+				)
+				for it.Next() {
+					all = append(all, it.Event())
+				}
+				if err := it.Err(); err != nil {
+					return err
+				}
+				return e.out.list(all)
+			}
+
+			events, page, err := e.client.Events.List(e.ctx, l.opts)
+			if err != nil {
+				return err
+			}
+			return printPage(e, events, page)
+		}
+	}},
+
+	"get": {"Get an event by ID", func(fs *flag.FlagSet) func(env, []string) error {
+		return func(e env, args []string) error {
+			id, err := idArg(args)
+			if err != nil {
+				return err
+			}
+
+			event, err := e.client.Events.Get(e.ctx, id)
+			if err != nil {
+				return err
+			}
+			return e.out.one(event)
+		}
+	}},
+
+	"create": {"Create an event", func(fs *flag.FlagSet) func(env, []string) error {
+		var (
+			name   = fs.String("name", "", "Name of the event")
+			starts = fs.String("starts-at", "", "Start, RFC 3339, e.g. 2022-03-04T19:00:00Z")
+			ends   = fs.String("ends-at", "", "End, RFC 3339")
+		)
+		return func(e env, args []string) error {
+			var event = models.Event{Name: *name}
+
+			for _, t := range []struct {
+				value string
+				dst   **time.Time
+			}{{*starts, &event.Starts_at}, {*ends, &event.Ends_at}} {
+				if t.value == "" {
+					continue
+				}
+				v, err := time.Parse(time.RFC3339, t.value)
+				if err != nil {
+					return fmt.Errorf("%q is not an RFC 3339 date, e.g. 2022-03-04T19:00:00Z", t.value)
+				}
+				*t.dst = &v
+			}
+
+			event, err := e.client.Events.Create(e.ctx, event)
+			if err != nil {
+				return err
+			}
+			return e.out.one(event)
+		}
+	}},
+}
+
+var participantCommands = map[string]command{
+	"list": {"List the participants", func(fs *flag.FlagSet) func(env, []string) error {
+		var l = listFlags(fs)
+		return func(e env, args []string) error {
+			if l.all {
+				var (
+					all []models.Participant
+					it  = e.client.Participants.Iter(e.ctx, l.opts)
+				)
+				for it.Next() {
+					all = append(all, it.Participant())
+				}
+				if err := it.Err(); err != nil {
+					return err
+				}
+				return e.out.list(all)
+			}
+
+			participants, page, err := e.client.Participants.List(e.ctx, l.opts)
+			if err != nil {
+				return err
+			}
+			return printPage(e, participants, page)
+		}
+	}},
+
+	"get": {"Get a participant by ID", func(fs *flag.FlagSet) func(env, []string) error {
+		return func(e env, args []string) error {
+			id, err := idArg(args)
+			if err != nil {
+				return err
+			}
+
+			participant, err := e.client.Participants.Get(e.ctx, id)
+			if err != nil {
+				return err
+			}
+			return e.out.one(participant)
+		}
+	}},
+
+	"create": {"Create a participant", func(fs *flag.FlagSet) func(env, []string) error {
+		var (
+			firstname = fs.String("firstname", "", "First name")
+			lastname  = fs.String("lastname", "", "Last name, optional")
+			age       = fs.Uint("age", 0, "Age, between 18 and 129")
+		)
+		return func(e env, args []string) error {
+			if *age > 255 {
+				return fmt.Errorf("%d is not a valid age", *age)
+			}
+
+			participant, err := e.client.Participants.Create(e.ctx, models.Participant{
+				Firstname: *firstname,
+				Lastname:  *lastname,
+				Age:       uint8(*age),
+			})
+			if err != nil {
+				return err
+			}
+			return e.out.one(participant)
+		}
+	}},
+}
+
+var ticketCommands = map[string]command{
+	"list": {"List the tickets, of an event or of a participant", func(fs *flag.FlagSet) func(env, []string) error {
+		var (
+			l           = listFlags(fs)
+			event       = fs.Int("event", 0, "Only the tickets of this event")
+			participant = fs.Int("participant", 0, "Only the tickets of this participant")
+		)
+		return func(e env, args []string) error {
+			var (
+				list = e.client.Tickets.List
+				iter = e.client.Tickets.Iter
+			)
+			switch {
+			case *event != 0 && *participant != 0:
+				return errors.New("--event and --participant can't be used together, see 'tickets check-in'")
+			case *event != 0:
+				list = func(ctx context.Context, opts client.ListOptions) ([]models.TicketView, models.Pagination, error) {
+					return e.client.Tickets.ListByEvent(ctx, *event, opts)
+				}
+				iter = func(ctx context.Context, opts client.ListOptions) *client.TicketIterator {
+					return e.client.Tickets.IterByEvent(ctx, *event, opts)
+				}
+			case *participant != 0:
+				list = func(ctx context.Context, opts client.ListOptions) ([]models.TicketView, models.Pagination, error) {
+					return e.client.Tickets.ListByParticipant(ctx, *participant, opts)
+				}
+				iter = func(ctx context.Context, opts client.ListOptions) *client.TicketIterator {
+					return e.client.Tickets.IterByParticipant(ctx, *participant, opts)
+				}
+			}
+
+			if l.all {
+				var (
+					all []models.TicketView
+					it  = iter(e.ctx, l.opts)
+				)
+				for it.Next() {
+					all = append(all, it.Ticket())
+				}
+				if err := it.Err(); err != nil {
+					return err
+				}
+				return e.out.list(all)
+			}
+
+			tickets, page, err := list(e.ctx, l.opts)
+			if err != nil {
+				return err
+			}
+			return printPage(e, tickets, page)
+		}
+	}},
+
+	"get": {"Get a ticket by ID", func(fs *flag.FlagSet) func(env, []string) error {
+		return func(e env, args []string) error {
+			id, err := idArg(args)
+			if err != nil {
+				return err
+			}
+
+			ticket, err := e.client.Tickets.Get(e.ctx, id)
+			if err != nil {
+				return err
+			}
+			return e.out.one(ticket)
+		}
+	}},
+
+	"register": {"Register a participant for an event", func(fs *flag.FlagSet) func(env, []string) error {
+		var event, participant = ticketFlags(fs)
+		return func(e env, args []string) error {
+			if *event == 0 || *participant == 0 {
+				return errUsage
+			}
+
+			ticket, err := e.client.Tickets.Create(e.ctx, *participant, *event)
+			if err != nil {
+				return err
+			}
+			return e.out.one(ticket)
+		}
+	}},
+
+	// The API doesn't record check-ins, it's the lookup done at the door
+	"check-in": {"Check that a participant has a ticket for an event, exit code 1 if not", func(fs *flag.FlagSet) func(env, []string) error {
+		var event, participant = ticketFlags(fs)
+		return func(e env, args []string) error {
+			if *event == 0 || *participant == 0 {
+				return errUsage
+			}
+
+			ticket, err := e.client.Tickets.Find(e.ctx, *event, *participant)
+			if client.IsNotFound(err) {
+				return fmt.Errorf("participant %d has no ticket for event %d", *participant, *event)
+			}
+			if err != nil {
+				return err
+			}
+			return e.out.one(ticket)
+		}
+	}},
+
+	"delete": {"Delete a ticket by ID", func(fs *flag.FlagSet) func(env, []string) error {
+		var version = fs.Uint("version", 0, "Only if the ticket is still at this version")
+		return func(e env, args []string) error {
+			id, err := idArg(args)
+			if err != nil {
+				return err
+			}
+			if err = e.client.Tickets.Delete(e.ctx, id, uint32(*version)); err != nil {
+				return err
+			}
+			if e.out.format == outputTable {
+				fmt.Fprintf(os.Stdout, "Ticket %d deleted\n", id)
+			}
+			return nil
+		}
+	}},
+}
+
+var persistenceCommands = map[string]command{
+	"build": {"Create the schemas and the sample data of a database", func(fs *flag.FlagSet) func(env, []string) error {
+		var (
+			persistence = fs.String("persistence", "psql", "psql or mysql")
+			dbname      = fs.String("dbname", "", "Database name")
+			user        = fs.String("user", "", "Database user")
+			password    = fs.String("password", "", "Database password, $WISERCTL_DB_PASSWORD if empty")
+		)
+		return func(e env, args []string) error {
+			if *dbname == "" || *user == "" {
+				return errUsage
+			}
+			if *password == "" {
+				*password = os.Getenv("WISERCTL_DB_PASSWORD")
+			}
+
+			msg, err := e.client.Persistence.Build(e.ctx, *persistence, models.DSN{Dbname: *dbname, User: *user, Password: *password})
+			if err != nil {
+				return err
+			}
+			if e.out.format == outputJSON {
+				return e.out.json(map[string]string{"message": msg})
+			}
+			_, err = fmt.Fprintln(os.Stdout, msg)
+			return err
+		}
+	}},
+}
+
+type list struct {
+	opts client.ListOptions
+	all  bool
+}
+
+func listFlags(fs *flag.FlagSet) *list {
+	var l = new(list)
+	fs.IntVar(&l.opts.Limit, "limit", 0, "Items per page, the default of the API if zero")
+	fs.IntVar(&l.opts.Offset, "offset", 0, "Items to skip")
+	fs.BoolVar(&l.opts.Desc, "desc", false, "Newest first")
+	fs.BoolVar(&l.all, "all", false, "Every page, not only the first one")
+	return l
+}
+
+func ticketFlags(fs *flag.FlagSet) (event, participant *int) {
+	return fs.Int("event", 0, "ID of the event"), fs.Int("participant", 0, "ID of the participant")
+}
+
+// printPage tells how many items are left out of the table
+func printPage(e env, items interface{}, page models.Pagination) error {
+	if err := e.out.list(items); err != nil {
+		return err
+	}
+
+	if shown := page.Offset + page.Limit; e.out.format == outputTable && shown < page.Total {
+		fmt.Fprintf(os.Stderr, "\n%d of %d, see --offset or --all\n", page.Limit, page.Total)
+	}
+	return nil
+}
+
+func idArg(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errUsage
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%q is not an ID", args[0])
+	}
+	return id, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Config is read from $WISERCTL_CONFIG or ~/.config/wiserctl/config.json,
+// the flags win over it, e.g.
+//
+//	{
+//		"base_url": "http://127.0.0.1:8000",
+//		"api_key": "",
+//		"token": "",
+//		"output": "table"
+//	}
+type Config struct {
+	BaseURL string `json:"base_url"`
+	APIKey  string `json:"api_key"` // Sent as X-API-Key
+	Token   string `json:"token"`   // Sent as Authorization: Bearer
+	Output  string `json:"output"`
+}
+
+const defaultBaseURL = "http://127.0.0.1:8000"
+
+func configPath() string {
+	if p := os.Getenv("WISERCTL_CONFIG"); p != "" {
+		return p
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "wiserctl", "config.json")
+}
+
+// loadConfig reads the file at path, a missing file is the default config
+// unless the path was given explicitly
+func loadConfig(path string, explicit bool) (Config, error) {
+	var cfg = Config{BaseURL: defaultBaseURL, Output: outputTable}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, errors.New(path + ": " + err.Error())
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	if cfg.Output == "" {
+		cfg.Output = outputTable
+	}
+	return cfg, nil
+}
@@ -0,0 +1,178 @@
+// wiserctl calls the API from the command line, e.g.
+//
+//	wiserctl events list -o csv
+//	wiserctl tickets register --event 3 --participant 9
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luisnquin/restapi-technical-test/src/client"
+)
+
+const usage = `Usage: wiserctl [--config file] [--url base-url] [-o table|json|csv] <resource> <command> [flags]
+
+Resources and commands:
+%s
+Run 'wiserctl <resource> <command> -h' for the flags of a command.
+`
+
+// env of a command, flags parsed and client ready
+type env struct {
+	ctx    context.Context
+	client *client.Client
+	out    printer
+	cfg    Config
+}
+
+// setup registers the flags of the command and returns what it runs
+type command struct {
+	summary string
+	setup   func(fs *flag.FlagSet) func(e env, args []string) error
+}
+
+var resources = map[string]map[string]command{
+	"events":       eventCommands,
+	"participants": participantCommands,
+	"tickets":      ticketCommands,
+	"persistence":  persistenceCommands,
+}
+
+// Wrong usage, exit code 2
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	var (
+		global  = flag.NewFlagSet("wiserctl", flag.ContinueOnError)
+		cfgPath = global.String("config", configPath(), "Config file")
+		baseURL = global.String("url", "", "Base URL of the API, e.g. "+defaultBaseURL)
+		output  = global.String("o", "", "Output format: table, json or csv")
+	)
+	global.Usage = printUsage
+
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	args = global.Args()
+
+	if len(args) < 2 {
+		printUsage()
+		return 2
+	}
+
+	cmd, ok := resources[args[0]][args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", strings.Join(args[:2], " "))
+		printUsage()
+		return 2
+	}
+
+	var explicit bool
+	global.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "config" })
+
+	cfg, err := loadConfig(*cfgPath, explicit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+
+	// The output can also come after the command
+	fs := flag.NewFlagSet("wiserctl "+args[0]+" "+args[1], flag.ContinueOnError)
+	fs.StringVar(output, "o", *output, "Output format: table, json or csv")
+	var exec = cmd.setup(fs)
+
+	positional, err := parse(fs, args[2:])
+	if err != nil {
+		return 2
+	}
+
+	if *baseURL != "" {
+		cfg.BaseURL = *baseURL
+	}
+	if *output != "" {
+		cfg.Output = *output
+	}
+	if !validOutput(cfg.Output) {
+		fmt.Fprintf(os.Stderr, "Unknown output format %q, it must be table, json or csv\n", cfg.Output)
+		return 2
+	}
+
+	var opts = []client.Option{client.WithHTTPClient(&http.Client{Timeout: time.Minute})}
+	if cfg.APIKey != "" {
+		opts = append(opts, client.WithHeader("X-API-Key", cfg.APIKey))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, client.WithHeader("Authorization", "Bearer "+cfg.Token))
+	}
+
+	c, err := client.New(cfg.BaseURL, opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Base URL:", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = exec(env{ctx: ctx, client: c, out: printer{w: os.Stdout, format: cfg.Output}, cfg: cfg}, positional)
+	switch {
+	case errors.Is(err, errUsage):
+		fs.Usage()
+		return 2
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// parse allows flags after the positional arguments, e.g. get 3 -o json
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if args = fs.Args(); len(args) == 0 {
+			return positional, nil
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+}
+
+func printUsage() {
+	var (
+		b     strings.Builder
+		names = make([]string, 0, len(resources))
+	)
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cmds := make([]string, 0, len(resources[name]))
+		for cmd := range resources[name] {
+			cmds = append(cmds, cmd)
+		}
+		sort.Strings(cmds)
+
+		for _, cmd := range cmds {
+			fmt.Fprintf(&b, "  %-28s %s\n", name+" "+cmd, resources[name][cmd].summary)
+		}
+	}
+	fmt.Fprintf(os.Stderr, usage, b.String())
+}
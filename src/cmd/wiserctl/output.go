@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+func validOutput(format string) bool {
+	return format == outputTable || format == outputJSON || format == outputCSV
+}
+
+// printer writes a list of structs, or a single one, with the columns
+// named as the JSON members
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func (p printer) one(item interface{}) error {
+	if p.format == outputJSON {
+		return p.json(item)
+	}
+	return p.rows([]interface{}{item})
+}
+
+// list takes a slice of structs
+func (p printer) list(items interface{}) error {
+	var v = reflect.ValueOf(items)
+
+	if p.format == outputJSON {
+		if v.Len() == 0 {
+			return p.json([]interface{}{})
+		}
+		return p.json(items)
+	}
+
+	var rows = make([]interface{}, v.Len())
+	for i := range rows {
+		rows[i] = v.Index(i).Interface()
+	}
+	return p.rows(rows)
+}
+
+func (p printer) json(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p printer) rows(rows []interface{}) error {
+	if len(rows) == 0 {
+		if p.format == outputTable {
+			_, err := fmt.Fprintln(p.w, "No results")
+			return err
+		}
+		return nil
+	}
+
+	var header = columns(reflect.TypeOf(rows[0]))
+
+	if p.format == outputCSV {
+		w := csv.NewWriter(p.w)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := w.Write(values(r)); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(header, "\t")))
+	for _, r := range rows {
+		fmt.Fprintln(w, strings.Join(values(r), "\t"))
+	}
+	return w.Flush()
+}
+
+func columns(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func values(item interface{}) []string {
+	var (
+		v      = reflect.ValueOf(item)
+		fields []string
+	)
+
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == "" {
+			continue
+		}
+
+		switch f := v.Field(i).Interface().(type) {
+		case time.Time:
+			fields = append(fields, f.Format(time.RFC3339))
+		case *time.Time:
+			if f == nil {
+				fields = append(fields, "")
+			} else {
+				fields = append(fields, f.Format(time.RFC3339))
+			}
+		default:
+			fields = append(fields, fmt.Sprint(f))
+		}
+	}
+	return fields
+}
+
+func jsonName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	default:
+		return name
+	}
+}
@@ -2,12 +2,115 @@ package constants
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
 const APIVersion string = "0.0.3" // Semantic Versioning
 
+// Commit and time of the build, injected with -ldflags, e.g.
+// -X github.com/luisnquin/restapi-technical-test/src/constants.Commit=$(git rev-parse HEAD)
+var (
+	Commit    string
+	BuildTime string
+)
+
+const (
+	MIMEApplicationProblemJSON    string = "application/problem+json"
+	MIMEApplicationMergePatchJSON string = "application/merge-patch+json"
+	MIMEApplicationJSONPatchJSON  string = "application/json-patch+json"
+	MIMETextCSV                   string = "text/csv"
+	MIMEApplicationXLSX           string = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MIMEApplicationNDJSON         string = "application/x-ndjson"
+	MIMETextCalendar              string = "text/calendar"
+	ProblemTypeBase               string = "/problems/" // Relative URI reference, see RFC 7807 section 3.1
+)
+
+// Right-hand side of the UIDs of the exported events, it must not change
+// or calendar clients would duplicate them
+const CalendarDomain string = "restapi-technical-test"
+
+const (
+	DefaultPageLimit int = 100
+	MaxPageLimit     int = 1000
+	MaxExportLimit   int = 100000 // Limit of the CSV, NDJSON and XML collections
+	MaxBatchSize     int = 1000   // Items per batch request
+)
+
+const (
+	MaxGraphQLDepth       int = 6     // Nested selections of a GraphQL query, e.g. events.items.tickets.participant.events
+	MaxGraphQLParallelism int = 10    // Resolvers of a GraphQL query running at the same time
+	MaxGraphQLCost        int = 50000 // Fields a GraphQL query may resolve, counted once per item of the lists they are in
+
+	MaxGraphQLBodySize int64 = 1 << 20 // 1 MiB per GraphQL request
+)
+
+const (
+	MaxImportSize     int64 = 32 << 20  // 32 MiB per imported file
+	MaxImportXMLSize  int64 = 128 << 20 // 128 MiB per part of an imported workbook, uncompressed
+	ImportProgressRow int   = 100       // Rows between progress messages
+)
+
+// Whether PUT, PATCH and DELETE must send the If-Match header
+var RequireIfMatch, _ = strconv.ParseBool(os.Getenv("REQUIRE_IF_MATCH"))
+
+// How long a stored response can be replayed for the same Idempotency-Key
+var IdempotencyTTL = func() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil || ttl <= 0 {
+		return time.Hour * 24
+	}
+	return ttl
+}()
+
+// Address of the gRPC server, the REST API one is :8000
+var GRPCAddress = func() string {
+	if address := os.Getenv("GRPC_ADDRESS"); address != "" {
+		return address
+	}
+	return ":9000"
+}()
+
+// Level of the logs, debug, info, warn or error, the queries are debug
+var LogLevel = os.Getenv("LOG_LEVEL")
+
+// Format of the logs, json or text
+var LogFormat = os.Getenv("LOG_FORMAT")
+
+// Exporter of the spans, otlp or stdout, none if unset. The OTLP one takes
+// the standard variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT.
+var TracesExporter = os.Getenv("OTEL_TRACES_EXPORTER")
+
+// Protocol of the OTLP exporter, grpc or http/protobuf
+var TracesProtocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+
+// Whether the server runs in production, APP_ENV=production
+var Production = os.Getenv("APP_ENV") == "production"
+
+// Whether POST /persistence/build is served, it drops every table. Off in
+// production unless PERSISTENCE_BUILD=true.
+var PersistenceBuild = func() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("PERSISTENCE_BUILD"))
+	if err != nil {
+		return !Production
+	}
+	return enabled
+}()
+
+// Address of the admin listener, /metrics and the persistence build are only
+// served there if it's set, otherwise on :8000 to the clients of the same host
+var AdminAddress = os.Getenv("ADMIN_ADDRESS")
+
+// Proxies in front of the server, comma separated CIDRs, e.g. 10.0.0.0/8.
+// The IP of the clients is taken from X-Forwarded-For only when the
+// request comes from one of them, otherwise it's the remote address.
+var TrustedProxies = os.Getenv("TRUSTED_PROXIES")
+
+// File the audit entries are appended to, the standard error if unset
+var AuditLog = os.Getenv("AUDIT_LOG")
+
 var Persistence = func() storage.Persistence {
 	persistence := os.Getenv("PERSISTENCE_NAME")
 
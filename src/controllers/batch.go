@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// BatchItem processes the i-th item of a batch with the statements
+// prepared by the handler, data is what the client gets for the item
+type BatchItem func(ctx context.Context, i int) (status int, data interface{}, err error)
+
+// Error of the i-th item of an atomic batch, rendered with the index
+// in the detail and in the field names, e.g. [3].age
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return "item " + strconv.Itoa(e.Index) + ": " + e.Err.Error()
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// Atomic reads the atomic query parameter, true unless it's false
+func Atomic(c echo.Context) (bool, error) {
+	param := c.QueryParam("atomic")
+	if param == "" {
+		return true, nil
+	}
+
+	atomic, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, Invalid("The atomic parameter cannot be processed as boolean", models.FieldError{
+			Field:   "atomic",
+			Message: "must be true or false",
+		})
+	}
+	return atomic, nil
+}
+
+// DecodeBatch decodes the JSON array of the request body into items,
+// a pointer to a slice, and returns its length
+func DecodeBatch(c echo.Context, items interface{}) (int, error) {
+	if err := json.NewDecoder(c.Request().Body).Decode(items); err != nil {
+		return 0, BadRequest("The request body must be a JSON array of items")
+	}
+
+	switch n := reflect.ValueOf(items).Elem().Len(); {
+	case n == 0:
+		return 0, BadRequest("The request body data is empty")
+	case n > constants.MaxBatchSize:
+		return 0, Invalid(fmt.Sprintf("A batch cannot have more than %d items", constants.MaxBatchSize))
+	default:
+		return n, nil
+	}
+}
+
+// Batch runs every item within tx and commits it. An atomic batch stops at
+// the first failing item and nothing is written, otherwise each item runs
+// in its own savepoint and the client gets a 207 with the result of each.
+func Batch(ctx context.Context, c echo.Context, tx *sql.Tx, atomic bool, n int, status int, item BatchItem) error {
+	if atomic {
+		var data = make([]interface{}, 0, n)
+
+		for i := 0; i < n; i++ {
+			_, d, err := item(ctx, i)
+			if err != nil {
+				return &BatchItemError{Index: i, Err: err}
+			}
+			if d != nil {
+				data = append(data, d)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return Internal("The transaction could not be committed", err)
+		}
+
+		if len(data) == 0 {
+			return Respond(c, status, nil)
+		}
+		return Respond(c, status, data)
+	}
+
+	var results = make([]models.BatchResult, n)
+
+	for i := 0; i < n; i++ {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item;"); err != nil {
+			return Internal("The savepoint could not be created", err)
+		}
+
+		s, d, err := item(ctx, i)
+		if err != nil {
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item;"); err != nil {
+				return Internal("The item could not be rolled back", err)
+			}
+
+			p := asProblem(err)
+			results[i] = models.BatchResult{
+				Index:  i,
+				Status: p.status,
+				Error: &models.Problem{
+					Type:   problemType(p.status),
+					Title:  http.StatusText(p.status),
+					Status: p.status,
+					Detail: p.detail,
+					Errors: p.fields,
+				},
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item;"); err != nil {
+			return Internal("The savepoint could not be released", err)
+		}
+		results[i] = models.BatchResult{Index: i, Status: s, Data: d}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Internal("The transaction could not be committed", err)
+	}
+	return Respond(c, http.StatusMultiStatus, results)
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+const (
+	icsTime    = "20060102T150405Z" // UTC form of DATE-TIME, RFC 5545 section 3.3.5
+	icsLineLen = 75                 // Octets per line before folding, section 3.1
+)
+
+// CalendarWriter sends events as an iCalendar object (RFC 5545) as they are
+// scanned. Only the events with a start date can be written.
+type CalendarWriter struct {
+	c       echo.Context
+	name    string
+	stamp   string
+	started bool
+}
+
+// NewCalendar starts a calendar, name is shown by the clients that
+// subscribe to it and the file name of the download
+func NewCalendar(c echo.Context, name string) *CalendarWriter {
+	return &CalendarWriter{
+		c:     c,
+		name:  name,
+		stamp: time.Now().UTC().Format(icsTime),
+	}
+}
+
+// Add writes an event, the UID only depends on its ID and the SEQUENCE on
+// its version, clients update the entries they already have
+func (w *CalendarWriter) Add(e models.Event) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	var lines = []string{
+		"BEGIN:VEVENT",
+		"UID:" + EventUID(e.Id),
+		"SEQUENCE:" + strconv.FormatUint(uint64(e.Version-1), 10),
+		"DTSTAMP:" + w.stamp,
+		"CREATED:" + e.Created_at.UTC().Format(icsTime),
+		"DTSTART:" + e.Starts_at.UTC().Format(icsTime),
+	}
+	if e.Ends_at != nil {
+		lines = append(lines, "DTEND:"+e.Ends_at.UTC().Format(icsTime))
+	}
+	lines = append(lines, "SUMMARY:"+escapeText(e.Name), "END:VEVENT")
+
+	return w.write(lines...)
+}
+
+// Close ends the calendar, an empty one is still valid
+func (w *CalendarWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	return w.write("END:VCALENDAR")
+}
+
+func (w *CalendarWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	var res = w.c.Response()
+
+	res.Header().Set(echo.HeaderContentType, constants.MIMETextCalendar+"; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, `inline; filename="`+w.name+`.ics"`)
+	res.WriteHeader(http.StatusOK)
+
+	return w.write(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//restapi-technical-test//API "+constants.APIVersion+"//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:"+escapeText(w.name),
+	)
+}
+
+func (w *CalendarWriter) write(lines ...string) error {
+	var b strings.Builder
+	for _, l := range lines {
+		fold(&b, l)
+	}
+
+	_, err := w.c.Response().Write([]byte(b.String()))
+	return err
+}
+
+// EventUID identifies an event in every calendar it is exported to
+func EventUID(id uint16) string {
+	return "event-" + strconv.FormatUint(uint64(id), 10) + "@" + constants.CalendarDomain
+}
+
+// fold splits a content line in lines of 75 octets at most, the next ones
+// starting with a space, without breaking UTF-8 sequences
+func fold(b *strings.Builder, line string) {
+	var limit = icsLineLen
+
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i])
+		b.WriteString("\r\n ")
+		line = line[i:]
+		limit = icsLineLen - 1 // The leading space counts
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// Escapes of the TEXT values, RFC 5545 section 3.3.11
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// WithExtension serves the route with other when the last param ends with
+// ext, e.g. /event/1.ics, the router takes the extension as part of it
+func WithExtension(ext string, h, other echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var values = c.ParamValues()
+
+		if n := len(values); n > 0 && strings.HasSuffix(values[n-1], ext) {
+			values[n-1] = strings.TrimSuffix(values[n-1], ext)
+			c.SetParamValues(values...)
+			return other(c)
+		}
+		return h(c)
+	}
+}
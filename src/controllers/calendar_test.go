@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "short", line: "VERSION:2.0", want: "VERSION:2.0\r\n"},
+		{name: "75 octets", line: strings.Repeat("a", 75), want: strings.Repeat("a", 75) + "\r\n"},
+		{name: "76 octets", line: strings.Repeat("a", 76), want: strings.Repeat("a", 75) + "\r\n a\r\n"},
+		{
+			// The space of the next lines counts, 74 octets after it
+			name: "150 octets",
+			line: strings.Repeat("a", 150),
+			want: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a\r\n",
+		},
+		{
+			// The 75th octet is the middle of an é, it goes to the next line
+			name: "2-octet runes",
+			line: "SUMMARY:" + strings.Repeat("é", 40),
+			want: "SUMMARY:" + strings.Repeat("é", 33) + "\r\n " + strings.Repeat("é", 7) + "\r\n",
+		},
+		{
+			name: "4-octet runes",
+			line: "X:" + strings.Repeat("😀", 20),
+			want: "X:" + strings.Repeat("😀", 18) + "\r\n " + strings.Repeat("😀", 2) + "\r\n",
+		},
+		{
+			name: "3-octet runes over three lines",
+			line: strings.Repeat("€", 60),
+			want: strings.Repeat("€", 25) + "\r\n " + strings.Repeat("€", 24) + "\r\n " + strings.Repeat("€", 11) + "\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			fold(&b, tt.line)
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("fold() =\n%q\nwant\n%q", got, tt.want)
+			}
+			for _, l := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+				if len(l) > icsLineLen || !utf8.ValidString(l) {
+					t.Errorf("The line %q has %d octets or breaks a rune", l, len(l))
+				}
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Fest", want: "Fest"},
+		{text: `C:\fest`, want: `C:\\fest`},
+		{text: "Rock; Pop, Jazz", want: `Rock\; Pop\, Jazz`},
+		{text: "one\ntwo\r\nthree\rfour", want: `one\ntwo\nthree\nfour`},
+		// Colons and quotes are TEXT as they are
+		{text: `Fest: "live"`, want: `Fest: "live"`},
+		{text: `\n`, want: `\\n`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeText(tt.text); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarWriter(t *testing.T) {
+	var (
+		created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		starts  = time.Date(2024, 6, 1, 20, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+		ends    = starts.Add(3 * time.Hour)
+	)
+
+	c, rec := request(http.MethodGet, "", nil)
+
+	cal := NewCalendar(c, "Fests, 2024")
+	cal.stamp = "20240101T000000Z"
+
+	events := []models.Event{
+		{Id: 1, Name: "Rock; Pop", Created_at: created, Starts_at: &starts, Ends_at: &ends, Version: 1},
+		{Id: 2, Name: "Festival de música " + strings.Repeat("é", 30), Created_at: created, Starts_at: &starts, Version: 3},
+	}
+	for _, e := range events {
+		if err := cal.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//restapi-technical-test//API 0.0.3//EN",
+		"CALSCALE:GREGORIAN",
+		`X-WR-CALNAME:Fests\, 2024`,
+		"BEGIN:VEVENT",
+		"UID:event-1@restapi-technical-test",
+		"SEQUENCE:0",
+		"DTSTAMP:20240101T000000Z",
+		"CREATED:20240102T030405Z",
+		"DTSTART:20240602T010000Z",
+		"DTEND:20240602T040000Z",
+		`SUMMARY:Rock\; Pop`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:event-2@restapi-technical-test",
+		"SEQUENCE:2",
+		"DTSTAMP:20240101T000000Z",
+		"CREATED:20240102T030405Z",
+		"DTSTART:20240602T010000Z",
+		"SUMMARY:Festival de música " + strings.Repeat("é", 23),
+		" " + strings.Repeat("é", 7),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+
+	if got := rec.Body.String(); got != want {
+		t.Errorf("calendar =\n%s\nwant\n%s", got, want)
+	}
+	if got := rec.Header().Get(echo.HeaderContentType); got != "text/calendar; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get(echo.HeaderContentDisposition); got != `inline; filename="Fests, 2024.ics"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+}
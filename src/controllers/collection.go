@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+const (
+	headerVary       = "Vary"
+	headerTotalCount = "X-Total-Count"
+	headerLink       = "Link"
+	headerTrailer    = "Trailer"
+
+	// Trailer of the streams that failed after their first row, the status
+	// and detail of the error, e.g. "500 An error was logged ..."
+	headerStreamError = "X-Stream-Error"
+)
+
+// ListWriter sends the rows of a collection as they are scanned. JSON is
+// the only format that needs all of them, for the envelope, CSV, NDJSON
+// and XML are written to the client row by row. An error after the first
+// row can't change the 200 anymore, the stream ends there and the error is
+// sent in its X-Stream-Error trailer, clients must check it before trusting
+// the rows. The XML document is also left unclosed.
+type ListWriter struct {
+	c       echo.Context
+	page    models.Pagination
+	format  string
+	root    string
+	columns []string
+	started bool
+	items   []interface{}
+	csv     *csv.Writer
+	json    *json.Encoder
+	xml     *xml.Encoder
+}
+
+// NewList starts a collection named root, e.g. events, whose items are of
+// the same type as proto. The page must be complete, total included.
+func NewList(c echo.Context, page models.Pagination, root string, proto interface{}) *ListWriter {
+	c.Response().Header().Add(headerVary, echo.HeaderAccept)
+
+	return &ListWriter{
+		c:       c,
+		page:    page,
+		format:  listFormat(c),
+		root:    root,
+		columns: columns(reflect.TypeOf(proto)),
+		items:   make([]interface{}, 0),
+	}
+}
+
+func (l *ListWriter) Add(item interface{}) error {
+	if l.format == "" {
+		l.items = append(l.items, item)
+		return nil
+	}
+
+	if err := l.start(); err != nil {
+		return err
+	}
+
+	switch l.format {
+	case constants.MIMETextCSV:
+		var fields = values(item)
+		for i, v := range fields {
+			fields[i] = neutralize(v)
+		}
+		return l.csv.Write(fields)
+
+	case constants.MIMEApplicationNDJSON:
+		return l.json.Encode(item)
+
+	default:
+		var (
+			start  = xml.StartElement{Name: xml.Name{Local: strings.TrimSuffix(l.root, "s")}}
+			fields = values(item)
+		)
+
+		if err := l.xml.EncodeToken(start); err != nil {
+			return err
+		}
+		for i, name := range l.columns {
+			if err := l.xml.EncodeElement(fields[i], xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return err
+			}
+		}
+		return l.xml.EncodeToken(start.End())
+	}
+}
+
+// Close sends the JSON envelope or ends the stream
+func (l *ListWriter) Close() error {
+	if l.format == "" {
+		return List(l.c, l.items, l.page)
+	}
+
+	if err := l.start(); err != nil {
+		return err
+	}
+
+	switch l.format {
+	case constants.MIMETextCSV:
+		l.csv.Flush()
+		return l.csv.Error()
+
+	case echo.MIMEApplicationXML:
+		if err := l.xml.EncodeToken(xml.EndElement{Name: xml.Name{Local: l.root}}); err != nil {
+			return err
+		}
+		return l.xml.Flush()
+	}
+	return nil
+}
+
+// The status can't change once the first row is written, the errors
+// before it are still rendered by the HTTPErrorHandler
+func (l *ListWriter) start() error {
+	if l.started {
+		return nil
+	}
+	l.started = true
+
+	var res = l.c.Response()
+
+	res.Header().Set(headerTotalCount, strconv.Itoa(l.page.Total))
+	res.Header().Set(headerTrailer, headerStreamError)
+
+	// A stream stops at its limit, MaxExportLimit by default, the rest of
+	// the collection is in the next one
+	if next := l.page.Offset + l.page.Limit; next < l.page.Total {
+		res.Header().Set(headerLink, "<"+l.next(next)+`>; rel="next"`)
+	}
+
+	switch l.format {
+	case constants.MIMETextCSV:
+		res.Header().Set(echo.HeaderContentType, constants.MIMETextCSV+"; charset=utf-8")
+		res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+l.root+`.csv"`)
+		res.WriteHeader(http.StatusOK)
+
+		l.csv = csv.NewWriter(res)
+		return l.csv.Write(l.columns)
+
+	case constants.MIMEApplicationNDJSON:
+		res.Header().Set(echo.HeaderContentType, constants.MIMEApplicationNDJSON)
+		res.WriteHeader(http.StatusOK)
+
+		l.json = json.NewEncoder(res)
+		return nil
+
+	default:
+		res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationXMLCharsetUTF8)
+		res.WriteHeader(http.StatusOK)
+
+		if _, err := res.Write([]byte(xml.Header)); err != nil {
+			return err
+		}
+
+		l.xml = xml.NewEncoder(res)
+		return l.xml.EncodeToken(xml.StartElement{
+			Name: xml.Name{Local: l.root},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "limit"}, Value: strconv.Itoa(l.page.Limit)},
+				{Name: xml.Name{Local: "offset"}, Value: strconv.Itoa(l.page.Offset)},
+				{Name: xml.Name{Local: "total"}, Value: strconv.Itoa(l.page.Total)},
+			},
+		})
+	}
+}
+
+// next is the path of the stream from the offset, with the same query
+func (l *ListWriter) next(offset int) string {
+	var u = *l.c.Request().URL
+
+	q := u.Query()
+	q.Set("limit", strconv.Itoa(l.page.Limit))
+	q.Set("offset", strconv.Itoa(offset))
+	u.RawQuery = q.Encode()
+
+	return u.Path + "?" + u.RawQuery
+}
+
+// listFormat negotiates the format of a collection from the Accept header,
+// the first supported media range wins, empty is the JSON envelope
+func listFormat(c echo.Context) string {
+	for _, r := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		mediaType, _, _ := mime.ParseMediaType(r)
+
+		switch mediaType {
+		case constants.MIMETextCSV, constants.MIMEApplicationNDJSON:
+			return mediaType
+		case echo.MIMEApplicationXML, echo.MIMETextXML:
+			return echo.MIMEApplicationXML
+		case echo.MIMEApplicationJSON, "application/*", "*/*":
+			return ""
+		}
+	}
+	return ""
+}
+
+// Columns of the streamed formats, the same names as in JSON
+func columns(t reflect.Type) []string {
+	var names = make([]string, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func values(item interface{}) []string {
+	var (
+		v      = reflect.ValueOf(item)
+		fields = make([]string, 0, v.NumField())
+	)
+
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == "" {
+			continue
+		}
+
+		switch f := v.Field(i).Interface().(type) {
+		case time.Time:
+			fields = append(fields, f.Format(time.RFC3339))
+		case *time.Time:
+			if f == nil {
+				fields = append(fields, "")
+			} else {
+				fields = append(fields, f.Format(time.RFC3339))
+			}
+		default:
+			fields = append(fields, fmt.Sprint(f))
+		}
+	}
+	return fields
+}
+
+func jsonName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	default:
+		return name
+	}
+}
+
+// Spreadsheets run cells starting with these characters as formulas, a
+// leading quote keeps them as text (CSV injection)
+func neutralize(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
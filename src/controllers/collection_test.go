@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+func TestListWriter(t *testing.T) {
+	var (
+		page  = models.Pagination{Limit: 2, Offset: 0, Total: 2}
+		items = []models.Participant{
+			{Id: 1, Firstname: `=HYPERLINK("http://x")`, Lastname: `Díaz, "Ana"`, Age: 30, Version: 1},
+			{Id: 2, Firstname: "<b>Eva</b> & co", Lastname: "two\nlines", Age: 41, Version: 2},
+		}
+	)
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "CSV",
+			accept:      constants.MIMETextCSV,
+			contentType: "text/csv; charset=utf-8",
+			body: "id,firstname,lastname,age,version\n" +
+				"1,\"'=HYPERLINK(\"\"http://x\"\")\",\"Díaz, \"\"Ana\"\"\",30,1\n" +
+				"2,<b>Eva</b> & co,\"two\nlines\",41,2\n",
+		},
+		{
+			name:        "NDJSON",
+			accept:      constants.MIMEApplicationNDJSON,
+			contentType: constants.MIMEApplicationNDJSON,
+			body: `{"id":1,"firstname":"=HYPERLINK(\"http://x\")","lastname":"Díaz, \"Ana\"","age":30,"version":1}` + "\n" +
+				`{"id":2,"firstname":"\u003cb\u003eEva\u003c/b\u003e \u0026 co","lastname":"two\nlines","age":41,"version":2}` + "\n",
+		},
+		{
+			name:        "XML",
+			accept:      echo.MIMETextXML,
+			contentType: echo.MIMEApplicationXMLCharsetUTF8,
+			body: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<participants limit="2" offset="0" total="2">` +
+				`<participant><id>1</id><firstname>=HYPERLINK(&#34;http://x&#34;)</firstname><lastname>Díaz, &#34;Ana&#34;</lastname><age>30</age><version>1</version></participant>` +
+				`<participant><id>2</id><firstname>&lt;b&gt;Eva&lt;/b&gt; &amp; co</firstname><lastname>two&#xA;lines</lastname><age>41</age><version>2</version></participant>` +
+				`</participants>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := request(http.MethodGet, echo.HeaderAccept, []string{tt.accept})
+
+			list := NewList(c, page, "participants", models.Participant{})
+			for _, p := range items {
+				if err := list.Add(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := list.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := rec.Header().Get(echo.HeaderContentType); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := rec.Body.String(); got != tt.body {
+				t.Errorf("body =\n%s\nwant\n%s", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestListWriterStreamError(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.GET("/participants", func(c echo.Context) error {
+		list := NewList(c, models.Pagination{Limit: 2, Total: 2}, "participants", models.Participant{})
+		if c.QueryParam("rows") != "" {
+			if err := list.Add(models.Participant{Id: 1, Firstname: "Ana"}); err != nil {
+				return err
+			}
+		}
+		return Internal("An error was logged while trying to process the payload", errors.New("connection reset"))
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		trailer string
+	}{
+		{name: "before the first row", path: "/participants", status: http.StatusInternalServerError},
+		{
+			name:    "after the first row",
+			path:    "/participants?rows=1",
+			status:  http.StatusOK,
+			trailer: "500 An error was logged while trying to process the payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(echo.HeaderAccept, constants.MIMEApplicationNDJSON)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+			if got := res.Trailer.Get(headerStreamError); got != tt.trailer {
+				t.Errorf("%s trailer = %q, want %q", headerStreamError, got, tt.trailer)
+			}
+		})
+	}
+}
+
+func TestNeutralize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Ana", want: "Ana"},
+		{value: "42", want: "42"},
+		{value: "=SUM(A1:A2)", want: "'=SUM(A1:A2)"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@cmd", want: "'@cmd"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "\r=1", want: "'\r=1"},
+		// Only the first character runs a formula
+		{value: "a=1", want: "a=1"},
+	}
+
+	for _, tt := range tests {
+		if got := neutralize(tt.value); got != tt.want {
+			t.Errorf("neutralize(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
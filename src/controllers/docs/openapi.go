@@ -0,0 +1,276 @@
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// The subset of OpenAPI 3.1 used to describe this API
+type (
+	Document struct {
+		OpenAPI           string              `json:"openapi"`
+		JSONSchemaDialect string              `json:"jsonSchemaDialect"`
+		Info              Info                `json:"info"`
+		Tags              []Tag               `json:"tags"`
+		Paths             map[string]PathItem `json:"paths"`
+		Components        Components          `json:"components"`
+	}
+
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Tag struct {
+		Name string `json:"name"`
+	}
+
+	// Operations by method in lower case, e.g. get
+	PathItem map[string]*Operation
+
+	Operation struct {
+		OperationId string                `json:"operationId"`
+		Summary     string                `json:"summary"`
+		Tags        []string              `json:"tags"`
+		Parameters  []Parameter           `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]Response   `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+	}
+
+	Parameter struct {
+		Name        string `json:"name"`
+		In          string `json:"in"`
+		Description string `json:"description,omitempty"`
+		Required    bool   `json:"required,omitempty"`
+		Schema      Schema `json:"schema"`
+	}
+
+	RequestBody struct {
+		Required bool                 `json:"required"`
+		Content  map[string]MediaType `json:"content"`
+	}
+
+	MediaType struct {
+		Schema Schema `json:"schema"`
+	}
+
+	Response struct {
+		Ref         string               `json:"$ref,omitempty"`
+		Description string               `json:"description,omitempty"`
+		Headers     map[string]Header    `json:"headers,omitempty"`
+		Content     map[string]MediaType `json:"content,omitempty"`
+	}
+
+	Header struct {
+		Description string `json:"description,omitempty"`
+		Schema      Schema `json:"schema"`
+	}
+
+	Components struct {
+		Schemas         map[string]Schema         `json:"schemas"`
+		Responses       map[string]Response       `json:"responses"`
+		SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+	}
+
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Description  string `json:"description,omitempty"`
+		Name         string `json:"name,omitempty"`
+		In           string `json:"in,omitempty"`
+		Scheme       string `json:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+	}
+)
+
+//go:embed redoc.html
+var redoc []byte
+
+var (
+	once     sync.Once
+	document *Document
+)
+
+// OpenAPI serves the description of every route of the server
+func OpenAPI() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		once.Do(func() {
+			document = Build(c.Echo().Routes())
+		})
+		return c.JSON(http.StatusOK, document)
+	}
+}
+
+// UI renders the document with Redoc
+func UI() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.HTMLBlob(http.StatusOK, redoc)
+	}
+}
+
+// Build describes the routes, the ones without an entry in operations
+// are left out, see Undocumented
+func Build(routes []*echo.Route) *Document {
+	var (
+		s   = make(schemas)
+		doc = &Document{
+			OpenAPI:           "3.1.0",
+			JSONSchemaDialect: "https://spec.openapis.org/oas/3.1/dialect/base",
+			Info: Info{
+				Title:       "restapi-technical-test",
+				Version:     constants.APIVersion,
+				Description: "Events, participants and their tickets. Errors are application/problem+json (RFC 7807), or the legacy envelope when the client only accepts application/json.",
+			},
+			Paths: make(map[string]PathItem),
+		}
+		tags = make(map[string]bool)
+	)
+
+	var add = func(method, path, name string, op operation) {
+		o := op(s, name)
+		o.Parameters = append(pathParams(path), o.Parameters...)
+		if isProtected(path) {
+			o.Security = security
+			o.Responses[strconv.Itoa(http.StatusUnauthorized)] = errorRef(http.StatusUnauthorized)
+			o.Responses[strconv.Itoa(http.StatusForbidden)] = errorRef(http.StatusForbidden)
+			o.Responses[strconv.Itoa(http.StatusTooManyRequests)] = errorRef(http.StatusTooManyRequests)
+		}
+		if hasDeadline(path) {
+			o.Responses[strconv.Itoa(http.StatusGatewayTimeout)] = errorRef(http.StatusGatewayTimeout)
+		}
+
+		p := openAPIPath(path)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = make(PathItem)
+		}
+		doc.Paths[p][strings.ToLower(method)] = o
+
+		for _, t := range o.Tags {
+			tags[t] = true
+		}
+	}
+
+	for _, r := range routes {
+		if op, ok := operations[r.Method+" "+r.Path]; ok {
+			add(r.Method, r.Path, r.Name, op)
+		}
+	}
+	for key, op := range extensions {
+		kv := strings.SplitN(key, " ", 3)
+		add(kv[0], kv[1], kv[2], op)
+	}
+
+	for t := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: t})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	s.of(models.SuccessfulResponse{})
+	doc.Components = Components{Schemas: s, Responses: errorResponses(s, doc.Paths), SecuritySchemes: securitySchemes}
+	return doc
+}
+
+// Prefixes of the routes that require credentials and are rate limited, as
+// routers.Apply does
+var protected = []string{"/api/v1/", "/graphql", "/persistence/build/"}
+
+func isProtected(path string) bool {
+	for _, prefix := range protected {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prefixes of the routes with a deadline, see middleware.Deadline
+var deadlined = []string{"/api/v1/", "/graphql"}
+
+func hasDeadline(path string) bool {
+	for _, prefix := range deadlined {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Either of the schemes is enough
+var security = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+
+var securitySchemes = map[string]SecurityScheme{
+	"apiKey": {
+		Type:        "apiKey",
+		Description: "A key issued with authctl create-key",
+		Name:        auth.HeaderAPIKey,
+		In:          "header",
+	},
+	"bearer": {
+		Type:         "http",
+		Description:  "HS256 or RS256 token with the sub and exp claims",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	},
+}
+
+// Name echo gives to the routes a group with middleware adds to catch
+// every request under its prefix, they are not part of the API
+var notFound = runtime.FuncForPC(reflect.ValueOf(echo.NotFoundHandler).Pointer()).Name()
+
+// Undocumented lists the routes without an entry in operations, the tests
+// of the routers fail with any of them
+func Undocumented(routes []*echo.Route) []string {
+	var missing []string
+	for _, r := range routes {
+		if r.Name == notFound {
+			continue
+		}
+		if _, ok := operations[r.Method+" "+r.Path]; !ok {
+			missing = append(missing, r.Method+" "+r.Path+" ("+r.Name+")")
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+var pathParam = regexp.MustCompile(`:([\w-]+)`)
+
+// e.g. /api/v1/event/:id becomes /api/v1/event/{id}, escaped colons are
+// part of the path
+func openAPIPath(path string) string {
+	path = strings.ReplaceAll(path, `\:`, "\x00")
+	path = pathParam.ReplaceAllString(path, "{$1}")
+	return strings.ReplaceAll(path, "\x00", ":")
+}
+
+func pathParams(path string) []Parameter {
+	var params []Parameter
+
+	for _, m := range pathParam.FindAllStringSubmatchIndex(path, -1) {
+		if m[0] > 0 && path[m[0]-1] == '\\' {
+			continue
+		}
+
+		name := path[m[2]:m[3]]
+		schema := Schema{"type": "string"}
+		if name == "id" || strings.HasSuffix(name, "-id") {
+			schema = Schema{"type": "integer", "minimum": 1}
+		}
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	return params
+}
@@ -0,0 +1,488 @@
+package docs
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// operation describes a route, name is the one given in the routers
+type operation func(s schemas, name string) *Operation
+
+// Every route registered in routers.Apply, by method and path as echo has
+// them. The server doesn't start if one is missing, see Undocumented.
+var operations = map[string]operation{
+	"GET /openapi.json": spec(),
+	"GET /docs":         page(),
+	"GET /metrics":      metrics(),
+	"GET /healthz":      probe("Liveness, the process serves requests"),
+	"GET /readyz":       probe("Readiness, the database is reachable and has the last schema", http.StatusServiceUnavailable),
+	"GET /version":      version(),
+
+	"GET /persistence/help":                text("Persistence", "How to build the database"),
+	"POST /persistence/build/:persistence": build(),
+
+	"POST /graphql": graphQL(),
+
+	"GET /api/v1/events":                                       list("Events", "List the events", models.Event{}),
+	"GET /api/v1/events.ics":                                   calendar("Events", "Subscribe to the scheduled events"),
+	"GET /api/v1/event/:id":                                    get("Events", "Get an event", models.Event{}),
+	"GET /api/v1/event/:id/tickets":                            list("Events", "List the tickets of an event", models.TicketView{}).with(http.StatusNotFound),
+	"GET /api/v1/event/:id/participants":                       list("Events", "List the participants of an event", models.TicketView{}).with(http.StatusNotFound),
+	"GET /api/v1/event/:event-id/participant/:participant-id":  get("Tickets", "Get the ticket of a participant for an event", models.TicketView{}),
+	"POST /api/v1/event":                                       create("Events", "Create an event", models.Event{}, models.Event{}),
+	"POST /api/v1/event/:event-id/participant/:participant-id": create("Tickets", "Register a participant for an event", nil, models.Ticket{}).named("events.tickets.create").with(http.StatusNotFound),
+	"PUT /api/v1/event/:id":                                    update("Events", "Replace an event", models.Event{}),
+	"PATCH /api/v1/event/:id":                                  modify("Events", "Modify an event", models.Event{}),
+	"DELETE /api/v1/event/:id":                                 remove("Events", "Delete an event"),
+	"DELETE /api/v1/event/:id/participants":                    remove("Events", "Delete an event and its participants"),
+
+	"GET /api/v1/participants":                list("Participants", "List the participants", models.Participant{}),
+	"GET /api/v1/participant/:id":             get("Participants", "Get a participant", models.Participant{}),
+	"GET /api/v1/participant/:id/tickets":     list("Participants", "List the tickets of a participant", models.TicketView{}).with(http.StatusNotFound),
+	"GET /api/v1/participant/:id/agenda.ics":  calendar("Participants", "Agenda of the events a participant has a ticket for").with(http.StatusNotFound, http.StatusUnprocessableEntity),
+	"POST /api/v1/participant":                create("Participants", "Create a participant", models.Participant{}, models.Participant{}),
+	"POST /api/v1/participants\\:batch":       batch("Participants", "Create participants", []models.Participant{}, http.StatusCreated, models.Participant{}),
+	"POST /api/v1/participants\\:batchUpdate": batch("Participants", "Replace participants, the version of each one is its If-Match", []models.Participant{}, http.StatusOK, nil),
+	"POST /api/v1/participants\\:batchDelete": batch("Participants", "Delete participants by ID", []uint64{}, http.StatusOK, nil),
+	"POST /api/v1/import/participants":        importFile(),
+	"PUT /api/v1/participant/:id":             update("Participants", "Replace a participant", models.Participant{}),
+	"PATCH /api/v1/participant/:id":           modify("Participants", "Modify a participant", models.Participant{}),
+	"DELETE /api/v1/participant/:id":          remove("Participants", "Delete a participant"),
+
+	"GET /api/v1/tickets":                list("Tickets", "List the tickets", models.TicketView{}),
+	"GET /api/v1/ticket/:id":             get("Tickets", "Get a ticket", models.TicketView{}),
+	"POST /api/v1/ticket":                create("Tickets", "Register a participant for an event", models.Ticket{}, models.Ticket{}).with(http.StatusNotFound),
+	"POST /api/v1/tickets\\:batch":       batch("Tickets", "Create tickets", []models.Ticket{}, http.StatusCreated, models.Ticket{}),
+	"POST /api/v1/tickets\\:batchDelete": batch("Tickets", "Delete tickets by ID", []uint32{}, http.StatusOK, nil),
+	"PUT /api/v1/ticket/:id":             update("Tickets", "Replace a ticket", models.Ticket{}).with(http.StatusConflict),
+	"PATCH /api/v1/ticket/:id":           update("Tickets", "Change the participant or the event of a ticket", models.Ticket{}).with(http.StatusConflict),
+	"DELETE /api/v1/ticket/:id":          remove("Tickets", "Delete a ticket"),
+}
+
+// Paths served by a route of operations, by method, path and name
+var extensions = map[string]operation{
+	"GET /api/v1/event/:id.ics events.get.ics": calendar("Events", "Export an event, 404 if it has no schedule").with(http.StatusNotFound, http.StatusUnprocessableEntity),
+}
+
+// named replaces the name of the route as operationId, they must be unique
+func (op operation) named(id string) operation {
+	return func(s schemas, name string) *Operation {
+		return op(s, id)
+	}
+}
+
+// with adds error responses to the operation
+func (op operation) with(statuses ...int) operation {
+	return func(s schemas, name string) *Operation {
+		o := op(s, name)
+		for _, status := range statuses {
+			o.Responses[strconv.Itoa(status)] = errorRef(status)
+		}
+		return o
+	}
+}
+
+func newOperation(name, tag, summary string, statuses ...int) *Operation {
+	var o = &Operation{
+		OperationId: name,
+		Summary:     summary,
+		Tags:        []string{tag},
+		Responses:   make(map[string]Response, len(statuses)+1),
+	}
+	for _, status := range statuses {
+		o.Responses[strconv.Itoa(status)] = errorRef(status)
+	}
+	return o
+}
+
+func list(tag, summary string, item interface{}) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusUnprocessableEntity, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{
+			query("limit", "Items per page, "+strconv.Itoa(constants.DefaultPageLimit)+" by default, "+strconv.Itoa(constants.MaxExportLimit)+" in CSV, NDJSON and XML", Schema{"type": "integer", "minimum": 1, "maximum": constants.MaxExportLimit}),
+			query("offset", "Items to skip", Schema{"type": "integer", "minimum": 0}),
+			query("desc", "Newest first", Schema{"type": "boolean"}),
+		}
+
+		var page = envelope(s, Schema{"type": "array", "items": s.of(item)})
+		page["allOf"] = append(page["allOf"].([]Schema), Schema{"required": []string{"pagination"}})
+
+		var stream = MediaType{Schema: Schema{"type": "string"}}
+		o.Responses["200"] = Response{
+			Description: "The page of the collection, or a stream of up to " + strconv.Itoa(constants.MaxExportLimit) + " items",
+			Headers: map[string]Header{
+				"X-Total-Count": {Description: "Items of the collection, CSV, NDJSON and XML only", Schema: Schema{"type": "integer"}},
+				"Link":          {Description: `The rest of the stream, <...?limit=&offset=>; rel="next", CSV, NDJSON and XML only`, Schema: Schema{"type": "string"}},
+			},
+			Content: map[string]MediaType{
+				echo.MIMEApplicationJSON:        {Schema: page},
+				constants.MIMETextCSV:           stream,
+				constants.MIMEApplicationNDJSON: stream,
+				echo.MIMEApplicationXML:         stream,
+			},
+		}
+		return o
+	}
+}
+
+func get(tag, summary string, item interface{}) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{header("If-None-Match", "ETag of the copy the client has")}
+		o.Responses["200"] = Response{
+			Description: "The resource",
+			Headers:     map[string]Header{"ETag": etag()},
+			Content:     jsonContent(envelope(s, s.of(item))),
+		}
+		o.Responses["304"] = Response{Description: "The copy of the client is current"}
+		return o
+	}
+}
+
+// body is nil when everything is taken from the path
+func create(tag, summary string, body, item interface{}) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{idempotencyKey()}
+		if body != nil {
+			o.RequestBody = &RequestBody{Required: true, Content: jsonContent(s.of(body))}
+		}
+		o.Responses["201"] = Response{
+			Description: "Created",
+			Headers: map[string]Header{
+				"Location": {Description: "URL of the new resource", Schema: Schema{"type": "string"}},
+				"ETag":     etag(),
+			},
+			Content: jsonContent(envelope(s, s.of(item))),
+		}
+		return o
+	}
+}
+
+func update(tag, summary string, body interface{}) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed,
+			http.StatusUnprocessableEntity, http.StatusPreconditionRequired, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{ifMatch()}
+		o.RequestBody = &RequestBody{Required: true, Content: jsonContent(s.of(body))}
+		o.Responses["200"] = Response{
+			Description: "Updated",
+			Headers:     map[string]Header{"ETag": etag()},
+			Content:     jsonContent(envelope(s, nil)),
+		}
+		return o
+	}
+}
+
+func modify(tag, summary string, item interface{}) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnsupportedMediaType,
+			http.StatusUnprocessableEntity, http.StatusPreconditionRequired, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{ifMatch()}
+		o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			constants.MIMEApplicationMergePatchJSON: {Schema: s.of(item)},
+			constants.MIMEApplicationJSONPatchJSON: {Schema: Schema{"type": "array", "items": Schema{
+				"type":     "object",
+				"required": []string{"op", "path"},
+				"properties": map[string]Schema{
+					"op":    {"enum": []string{"add", "remove", "replace", "move", "copy", "test"}},
+					"path":  {"type": "string"},
+					"from":  {"type": "string"},
+					"value": {},
+				},
+			}}},
+		}}
+		o.Responses["200"] = Response{
+			Description: "The modified resource",
+			Headers:     map[string]Header{"ETag": etag()},
+			Content:     jsonContent(envelope(s, s.of(item))),
+		}
+		return o
+	}
+}
+
+func remove(tag, summary string) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed,
+			http.StatusUnprocessableEntity, http.StatusPreconditionRequired, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{ifMatch()}
+		o.Responses["200"] = Response{Description: "Deleted", Content: jsonContent(envelope(s, nil))}
+		return o
+	}
+}
+
+// item is nil when the items have no data in the response
+func batch(tag, summary string, body interface{}, status int, item interface{}) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{
+			idempotencyKey(),
+			query("atomic", "Whether the first failing item rolls back the whole batch, true by default", Schema{"type": "boolean"}),
+		}
+
+		var items = s.of(body)
+		items["minItems"], items["maxItems"] = 1, constants.MaxBatchSize
+		o.RequestBody = &RequestBody{Required: true, Content: jsonContent(items)}
+
+		var data Schema
+		if item != nil {
+			data = Schema{"type": "array", "items": s.of(item)}
+		}
+		o.Responses[strconv.Itoa(status)] = Response{
+			Description: "Every item succeeded",
+			Content:     jsonContent(envelope(s, data)),
+		}
+		o.Responses["207"] = Response{
+			Description: "Outcome of each item, atomic=false only",
+			Content:     jsonContent(envelope(s, Schema{"type": "array", "items": s.of(models.BatchResult{})})),
+		}
+		return o
+	}
+}
+
+func calendar(tag, summary string) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary, http.StatusInternalServerError)
+		o.Responses["200"] = Response{
+			Description: "iCalendar (RFC 5545), the UID of an event never changes",
+			Content:     map[string]MediaType{constants.MIMETextCalendar: {Schema: Schema{"type": "string"}}},
+		}
+		return o
+	}
+}
+
+func importFile() operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "Participants", "Import participants from a CSV or XLSX file", http.StatusBadRequest,
+			http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity, http.StatusInternalServerError)
+
+		o.Parameters = []Parameter{
+			query("event", "Event every imported participant is registered for", Schema{"type": "integer", "minimum": 1}),
+			query("dry_run", "Validate the file without importing it", Schema{"type": "boolean"}),
+			query("mapping", "Header of each column, e.g. firstname:Nombre,age:Edad", Schema{"type": "string"}),
+			query("delimiter", "Delimiter of the CSV files, a comma by default", Schema{"type": "string", "minLength": 1, "maxLength": 1}),
+		}
+
+		var file = Schema{"type": "string", "contentMediaType": "application/octet-stream"}
+		o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			constants.MIMETextCSV:         {Schema: Schema{"type": "string"}},
+			constants.MIMEApplicationXLSX: {Schema: file},
+			echo.MIMEMultipartForm: {Schema: Schema{
+				"type":       "object",
+				"required":   []string{"file"},
+				"properties": map[string]Schema{"file": file},
+			}},
+		}}
+		o.Responses["200"] = Response{
+			Description: "The report, or the progress every " + strconv.Itoa(constants.ImportProgressRow) + " rows followed by the report as NDJSON",
+			Content: map[string]MediaType{
+				echo.MIMEApplicationJSON:        {Schema: envelope(s, s.of(models.ImportReport{}))},
+				constants.MIMEApplicationNDJSON: {Schema: Schema{"type": "string"}},
+			},
+		}
+		s.of(models.ImportProgress{})
+		return o
+	}
+}
+
+func text(tag, summary string) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, tag, summary)
+		o.Responses["200"] = plain("Instructions")
+		return o
+	}
+}
+
+// Scraped by Prometheus
+func metrics() operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "Monitoring", "HTTP, database and domain metrics. Only from the host of the server or in the admin listener", http.StatusForbidden)
+		o.Responses["200"] = plain("Prometheus text format")
+		return o
+	}
+}
+
+// The probes answer with their status and checks, the failures too
+func probe(summary string, failures ...int) operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "Monitoring", summary)
+		for _, status := range append([]int{http.StatusOK}, failures...) {
+			o.Responses[strconv.Itoa(status)] = Response{Description: http.StatusText(status), Content: jsonContent(s.of(models.Health{}))}
+		}
+		return o
+	}
+}
+
+func version() operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "Monitoring", "Version of the API, commit and time of the build and persistence in use")
+		o.Responses["200"] = Response{Description: "OK", Content: jsonContent(s.of(models.Version{}))}
+		return o
+	}
+}
+
+// The build endpoint answers in plain text
+func build() operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "Persistence", "Create the schemas and the sample data of a database, PostgreSQL or MySQL. Only from the host of the server or in the admin listener, off in production")
+
+		o.Parameters = append(o.Parameters, Parameter{
+			Name: "confirm", In: "query", Required: true, Schema: Schema{"type": "string"},
+			Description: "The dbname of the body again, every table of the database is dropped",
+		})
+		o.RequestBody = &RequestBody{Required: true, Content: jsonContent(s.of(models.DSN{}))}
+		for _, status := range []int{http.StatusOK, http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusInternalServerError} {
+			o.Responses[strconv.Itoa(status)] = plain(http.StatusText(status))
+		}
+		return o
+	}
+}
+
+// The errors of a GraphQL request are in its response, the error statuses
+// are the ones of the request itself
+func graphQL() operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "GraphQL", "Query and modify events, participants and tickets with their relations",
+			http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusInternalServerError)
+
+		o.RequestBody = &RequestBody{Required: true, Content: jsonContent(Schema{
+			"type":     "object",
+			"required": []string{"query"},
+			"properties": map[string]Schema{
+				"query":         {"type": "string"},
+				"operationName": {"type": "string"},
+				"variables":     {"type": "object"},
+			},
+		})}
+		o.Responses["200"] = Response{
+			Description: "The data and the errors of the resolvers, the status of each one in extensions.status",
+			Content: jsonContent(Schema{
+				"type": "object",
+				"properties": map[string]Schema{
+					"data":   {"type": []string{"object", "null"}},
+					"errors": {"type": "array", "items": Schema{"type": "object"}},
+				},
+			}),
+		}
+		return o
+	}
+}
+
+func spec() operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "Documentation", "This document")
+		o.Responses["200"] = Response{Description: "OpenAPI 3.1", Content: jsonContent(Schema{"type": "object"})}
+		return o
+	}
+}
+
+func page() operation {
+	return func(s schemas, name string) *Operation {
+		var o = newOperation(name, "Documentation", "This document rendered by Redoc")
+		o.Responses["200"] = Response{Description: "HTML", Content: map[string]MediaType{echo.MIMETextHTML: {Schema: Schema{"type": "string"}}}}
+		return o
+	}
+}
+
+// The successful envelope with the data of the operation, none if nil
+func envelope(s schemas, data Schema) Schema {
+	var schema = Schema{"allOf": []Schema{s.of(models.SuccessfulResponse{})}}
+	if data != nil {
+		schema["allOf"] = append(schema["allOf"].([]Schema), Schema{
+			"required":   []string{"data"},
+			"properties": map[string]Schema{"data": data},
+		})
+	}
+	return schema
+}
+
+func jsonContent(schema Schema) map[string]MediaType {
+	return map[string]MediaType{echo.MIMEApplicationJSON: {Schema: schema}}
+}
+
+func plain(description string) Response {
+	return Response{Description: description, Content: map[string]MediaType{echo.MIMETextPlain: {Schema: Schema{"type": "string"}}}}
+}
+
+func query(name, description string, schema Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func header(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: Schema{"type": "string"}}
+}
+
+func ifMatch() Parameter {
+	return header("If-Match", "ETag of the version being modified, required when REQUIRE_IF_MATCH is set")
+}
+
+func idempotencyKey() Parameter {
+	var p = header("Idempotency-Key", "Retries with the same key get the first response replayed")
+	p.Schema["maxLength"] = 255
+	return p
+}
+
+func etag() Header {
+	return Header{Description: "Version of the resource", Schema: Schema{"type": "string"}}
+}
+
+// Errors are described once in the components, by status
+func errorRef(status int) Response {
+	return Response{Ref: "#/components/responses/" + errorName(status)}
+}
+
+// e.g. 412 is PreconditionFailed
+func errorName(status int) string {
+	var name string
+	for _, word := range strings.Fields(strings.ReplaceAll(http.StatusText(status), "-", " ")) {
+		name += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return name
+}
+
+// The error responses referenced by the operations
+func errorResponses(s schemas, paths map[string]PathItem) map[string]Response {
+	var statuses = make(map[int]bool)
+	for _, item := range paths {
+		for _, o := range item {
+			for status, r := range o.Responses {
+				if n, _ := strconv.Atoi(status); r.Ref != "" {
+					statuses[n] = true
+				}
+			}
+		}
+	}
+
+	var sorted = make([]int, 0, len(statuses))
+	for status := range statuses {
+		sorted = append(sorted, status)
+	}
+	sort.Ints(sorted)
+
+	var responses = make(map[string]Response, len(sorted))
+	for _, status := range sorted {
+		responses[errorName(status)] = Response{
+			Description: http.StatusText(status) + ", the legacy envelope when application/problem+json isn't accepted",
+			Content: map[string]MediaType{
+				constants.MIMEApplicationProblemJSON: {Schema: s.of(models.Problem{})},
+				echo.MIMEApplicationJSON:             {Schema: s.of(models.BadResponse{})},
+			},
+		}
+	}
+	return responses
+}
@@ -0,0 +1,144 @@
+package docs
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12), the dialect of OpenAPI 3.1
+type Schema map[string]interface{}
+
+// Members set by the database, ignored in the request bodies
+var readOnly = map[string]bool{"id": true, "created_at": true, "version": true}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas derives the schemas of the models types, named structs are
+// added to the components once and referenced from everywhere else
+type schemas map[string]Schema
+
+func (s schemas) of(v interface{}) Schema {
+	return s.typ(reflect.TypeOf(v))
+}
+
+func (s schemas) typ(t reflect.Type) Schema {
+	switch t {
+	case nil:
+		return Schema{}
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.typ(t.Elem())
+		if ref, ok := schema["$ref"]; ok {
+			return Schema{"oneOf": []Schema{{"$ref": ref}, {"type": "null"}}}
+		}
+		if typ, ok := schema["type"]; ok {
+			schema["type"] = []interface{}{typ, "null"}
+		}
+		return schema
+
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer", "format": "int" + bits(t)}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema := Schema{"type": "integer", "minimum": 0}
+		if t.Bits() < 64 {
+			schema["maximum"] = uint64(math.MaxUint64) >> (64 - t.Bits())
+		}
+		return schema
+
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+
+	case reflect.String:
+		return Schema{"type": "string"}
+
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": s.typ(t.Elem())}
+
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": s.typ(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, ok := s[t.Name()]; !ok {
+			s[t.Name()] = nil // Recursive types
+			s[t.Name()] = s.object(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + t.Name()}
+
+	default: // interface{}
+		return Schema{}
+	}
+}
+
+// Members are named as in JSON, the ones without omitempty are always sent
+func (s schemas) object(t reflect.Type) Schema {
+	var (
+		properties = make(map[string]Schema, t.NumField())
+		required   = make([]string, 0, t.NumField())
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+
+		// Embedded structs without a name are flattened by encoding/json
+		if f.Anonymous && tag[0] == "" && f.Type.Kind() == reflect.Struct {
+			embedded := s.object(f.Type)
+			for name, p := range embedded["properties"].(map[string]Schema) {
+				properties[name] = p
+			}
+			required = append(required, embedded["required"].([]string)...)
+			continue
+		}
+
+		name := tag[0]
+		if name == "" {
+			name = f.Name
+		}
+
+		properties[name] = s.typ(f.Type)
+		if readOnly[name] {
+			properties[name]["readOnly"] = true
+		}
+		if !contains(tag[1:], "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return Schema{"type": "object", "properties": properties, "required": required}
+}
+
+func bits(t reflect.Type) string {
+	if t.Bits() <= 32 {
+		return "32"
+	}
+	return "64"
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// Errors returned by the handlers, the status code of each one
+// is decided by the HTTPErrorHandler.
+type (
+	BadRequestError struct {
+		Detail string
+	}
+
+	ValidationError struct {
+		Detail string
+		Fields []models.FieldError
+	}
+
+	NotFoundError struct {
+		Detail string
+	}
+
+	UnauthorizedError struct {
+		Detail string
+	}
+
+	ForbiddenError struct {
+		Detail string
+	}
+
+	ConflictError struct {
+		Detail string
+	}
+
+	UnsupportedMediaTypeError struct {
+		Detail string
+	}
+
+	PreconditionFailedError struct {
+		Detail string
+	}
+
+	PreconditionRequiredError struct {
+		Detail string
+	}
+
+	TooManyRequestsError struct {
+		Detail string
+	}
+
+	TooLargeError struct {
+		Detail string
+	}
+
+	InternalError struct {
+		Detail string
+		Err    error // Never exposed to the client
+	}
+)
+
+func BadRequest(detail string) *BadRequestError {
+	return &BadRequestError{Detail: detail}
+}
+
+func Invalid(detail string, fields ...models.FieldError) *ValidationError {
+	return &ValidationError{Detail: detail, Fields: fields}
+}
+
+func NotFound(detail string) *NotFoundError {
+	return &NotFoundError{Detail: detail}
+}
+
+func Unauthorized(detail string) *UnauthorizedError {
+	return &UnauthorizedError{Detail: detail}
+}
+
+func Forbidden(detail string) *ForbiddenError {
+	return &ForbiddenError{Detail: detail}
+}
+
+func TooManyRequests(detail string) *TooManyRequestsError {
+	return &TooManyRequestsError{Detail: detail}
+}
+
+func TooLarge(detail string) *TooLargeError {
+	return &TooLargeError{Detail: detail}
+}
+
+func Conflict(detail string) *ConflictError {
+	return &ConflictError{Detail: detail}
+}
+
+func UnsupportedMediaType(detail string) *UnsupportedMediaTypeError {
+	return &UnsupportedMediaTypeError{Detail: detail}
+}
+
+func PreconditionFailed(detail string) *PreconditionFailedError {
+	return &PreconditionFailedError{Detail: detail}
+}
+
+func PreconditionRequired(detail string) *PreconditionRequiredError {
+	return &PreconditionRequiredError{Detail: detail}
+}
+
+func Internal(detail string, err error) *InternalError {
+	return &InternalError{Detail: detail, Err: err}
+}
+
+// Rejected is the error of a query of the request that failed, fallback,
+// e.g. a NotFound or a BadRequest, unless ctx ended before: the query was
+// interrupted and didn't get to tell, that's an InternalError, a 504 once
+// the deadline passed.
+func Rejected(ctx context.Context, err error, fallback error) error {
+	if ctx.Err() != nil {
+		return Internal("The query was interrupted", err)
+	}
+	return fallback
+}
+
+func (e *BadRequestError) Error() string { return e.Detail }
+func (e *ValidationError) Error() string { return e.Detail }
+func (e *NotFoundError) Error() string   { return e.Detail }
+func (e *ConflictError) Error() string   { return e.Detail }
+
+func (e *UnauthorizedError) Error() string { return e.Detail }
+func (e *ForbiddenError) Error() string    { return e.Detail }
+
+func (e *UnsupportedMediaTypeError) Error() string { return e.Detail }
+func (e *PreconditionFailedError) Error() string   { return e.Detail }
+func (e *PreconditionRequiredError) Error() string { return e.Detail }
+func (e *TooManyRequestsError) Error() string      { return e.Detail }
+func (e *TooLargeError) Error() string             { return e.Detail }
+
+func (e *InternalError) Error() string {
+	if e.Err != nil {
+		return e.Detail + ": " + e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *InternalError) Unwrap() error {
+	return e.Err
+}
+
+// IntParam parses a path parameter as integer
+func IntParam(c echo.Context, name string) (int, error) {
+	n, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		return 0, Invalid("The "+name+" parameter cannot be processed as integer", models.FieldError{
+			Field:   name,
+			Message: "must be an integer",
+		})
+	}
+	return n, nil
+}
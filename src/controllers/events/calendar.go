@@ -0,0 +1,115 @@
+package events
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// Calendar exports an event as iCalendar, e.g. /event/1.ics
+func Calendar() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var db = storage.Get(constants.Persistence)
+
+		id, err := controllers.IntParam(c, "id")
+		if err != nil {
+			return err
+		}
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		ctx := c.Request().Context()
+
+		event, err := repository.GetEvent(ctx, db, int64(id))
+		if err != nil {
+			return err
+		}
+		if event.Starts_at == nil {
+			return controllers.NotFound("The event has no schedule yet")
+		}
+
+		var cal = controllers.NewCalendar(c, "event-"+strconv.Itoa(id))
+		if err = cal.Add(event); err != nil {
+			return err
+		}
+		return cal.Close()
+	}
+}
+
+// Feed exports every scheduled event as iCalendar, clients can subscribe
+// to it to keep up with the changes
+func Feed() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var db = storage.Get(constants.Persistence)
+
+		if err := db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+
+		defer func() {
+			if err := db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		ctx := c.Request().Context()
+
+		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT id, name, created_at, starts_at, ends_at, version FROM events WHERE starts_at IS NOT NULL ORDER BY starts_at ASC LIMIT $1;"
+		case storage.MySQL:
+			q = "SELECT id, name, created_at, starts_at, ends_at, version FROM events WHERE starts_at IS NOT NULL ORDER BY starts_at ASC LIMIT ?;"
+		}
+
+		stmt, err := db.PrepareContext(ctx, q)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = stmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		rows, err := stmt.QueryContext(ctx, constants.MaxExportLimit)
+		if err != nil {
+			return controllers.Internal("There was an error when tried to bring the payload", err)
+		}
+		defer func() {
+			if err = rows.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var cal = controllers.NewCalendar(c, "events")
+		for rows.Next() {
+			var e models.Event
+
+			if err = rows.Scan(&e.Id, &e.Name, &e.Created_at, &e.Starts_at, &e.Ends_at, &e.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = cal.Add(e); err != nil {
+				return err
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
+		}
+
+		return cal.Close()
+	}
+}
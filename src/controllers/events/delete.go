@@ -1,16 +1,14 @@
 package events
 
 import (
-	"context"
-	"fmt"
+	"database/sql"
 	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/luisnquin/restapi-technical-test/src/constants"
-	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
@@ -18,141 +16,38 @@ func RemoveById() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var db = storage.Get(constants.Persistence)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable entity",
-							"message": "The ID parameter cannot be used as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal server error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal server error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
-
 		defer func() {
 			if err = db.Close(); err != nil {
 				panic(err)
 			}
 		}()
 
-		var q string
-		switch constants.Persistence {
-		case storage.PostgreSQL:
-			q = "DELETE FROM events WHERE id = $1;"
-		case storage.MySQL:
-			q = "DELETE FROM events WHERE id = ?;"
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
 
-		stmt, err := db.PrepareContext(ctx, q)
+		version, err := controllers.Version(ctx, db, "events", id)
+		if err == sql.ErrNoRows {
+			return controllers.NotFound("Event not found")
+		}
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The event version could not be retrieved", err)
 		}
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
 
-		r, err := stmt.ExecContext(ctx, id)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The provided ID was rejected, not valid",
-						},
-					},
-				},
-			})
+		if err = controllers.CheckIfMatch(c, version); err != nil {
+			return err
 		}
-		if i, _ := r.RowsAffected(); i == 0 {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "The event was not found",
-						},
-					},
-				},
-			})
+
+		if err = repository.DeleteEvent(ctx, db, int64(id), &version); err != nil {
+			return err
 		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.delete",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-		})
+		return controllers.Respond(c, http.StatusOK, nil)
 	}
 }
 
@@ -160,47 +55,13 @@ func RemoveByIdWithParticipants() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var db = storage.Get(constants.Persistence)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID parameter cannot be used as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 		defer func() {
 			if err = db.Close(); err != nil {
@@ -208,146 +69,14 @@ func RemoveByIdWithParticipants() echo.HandlerFunc {
 			}
 		}()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
 
-		var q string
-		switch constants.Persistence {
-		case storage.PostgreSQL:
-			q = "DELETE FROM participants WHERE id IN (SELECT participant FROM tickets WHERE event = $1);"
-		case storage.MySQL:
-			q = "DELETE FROM participants WHERE id IN (SELECT participant FROM tickets WHERE event = ?);"
-		}
-
-		stmt, err := db.PrepareContext(ctx, q)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
-		}
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
-
-		_, err = stmt.ExecContext(ctx, id)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The ID provided was rejected, not valid",
-						},
-					},
-				},
-			})
-		}
-
-		switch constants.Persistence {
-		case storage.PostgreSQL:
-			q = "DELETE FROM events WHERE id = $1;"
-		case storage.MySQL:
-			q = "DELETE FROM events WHERE id = ?;"
-		}
-
-		stmt, err = db.PrepareContext(ctx, q)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
-		}
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
-
-		r, err := stmt.ExecContext(ctx, id)
+		err = repository.DeleteEventWithParticipants(ctx, db, int64(id), func(version uint32) error {
+			return controllers.CheckIfMatch(c, version)
+		})
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The ID provided was rejected, not valid",
-						},
-					},
-				},
-			})
+			return err
 		}
-		if i, _ := r.RowsAffected(); i == 0 {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": fmt.Sprintf("The event with ID %d was not found", id),
-						},
-					},
-				},
-			})
-		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.delete",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-		})
+		return controllers.Respond(c, http.StatusOK, nil)
 	}
 }
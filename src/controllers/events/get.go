@@ -1,15 +1,14 @@
 package events
 
 import (
-	"context"
-	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
 	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
@@ -22,22 +21,13 @@ func Fetch() echo.HandlerFunc {
 
 		desc, _ := strconv.ParseBool(c.QueryParam("desc"))
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		page, err := controllers.Page(c)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -46,32 +36,28 @@ func Fetch() echo.HandlerFunc {
 			}
 		}()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-		defer cancel()
+		ctx := c.Request().Context()
+
+		page.Total, err = controllers.Count(ctx, db, "SELECT COUNT(*) FROM events;")
+		if err != nil {
+			return controllers.Internal("The events could not be counted", err)
+		}
 
 		var q string
-		if desc {
-			q = "SELECT * FROM events ORDER BY id DESC;"
-		} else {
-			q = "SELECT * FROM events;"
+		switch {
+		case constants.Persistence == storage.PostgreSQL && desc:
+			q = "SELECT id, name, created_at, starts_at, ends_at, version FROM events ORDER BY id DESC LIMIT $1 OFFSET $2;"
+		case constants.Persistence == storage.MySQL && desc:
+			q = "SELECT id, name, created_at, starts_at, ends_at, version FROM events ORDER BY id DESC LIMIT ? OFFSET ?;"
+		case constants.Persistence == storage.PostgreSQL:
+			q = "SELECT id, name, created_at, starts_at, ends_at, version FROM events ORDER BY id ASC LIMIT $1 OFFSET $2;"
+		case constants.Persistence == storage.MySQL:
+			q = "SELECT id, name, created_at, starts_at, ends_at, version FROM events ORDER BY id ASC LIMIT ? OFFSET ?;"
 		}
 
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason": "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
 			if err = stmt.Close(); err != nil {
@@ -79,23 +65,9 @@ func Fetch() echo.HandlerFunc {
 			}
 		}()
 
-		rows, err := stmt.QueryContext(ctx)
+		rows, err := stmt.QueryContext(ctx, page.Limit, page.Offset)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal server error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "There was an error when tried to bring the payload",
-						},
-					},
-				},
-			})
+			return controllers.Internal("There was an error when tried to bring the payload", err)
 		}
 
 		defer func() {
@@ -104,44 +76,22 @@ func Fetch() echo.HandlerFunc {
 			}
 		}()
 
-		var events models.Events
+		var list = controllers.NewList(c, page, "events", models.Event{})
 		for rows.Next() {
 			var e models.Event
 
-			if err = rows.Scan(&e.Id, &e.Name, &e.Created_at); err != nil {
-				return c.JSON(http.StatusConflict, models.BadResponse{
-					APIVersion: constants.APIVersion,
-					Method:     "events.get",
-					Context:    c.Request().URL.String(),
-					Error: models.Error{
-						Code:    409,
-						Message: "Conflict",
-						Errors: []map[string]interface{}{
-							{
-								"reason":  "Conflict",
-								"message": "An error was logged while trying to process the payload",
-							},
-						},
-					},
-				})
+			if err = rows.Scan(&e.Id, &e.Name, &e.Created_at, &e.Starts_at, &e.Ends_at, &e.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = list.Add(e); err != nil {
+				return err
 			}
-			events = append(events, e)
 		}
-
-		if len(events) == 0 {
-			return c.JSON(http.StatusNoContent, models.SuccessfulResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-			})
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
 		}
 
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.get",
-			Context:    c.Request().URL.String(),
-			Data:       events,
-		})
+		return list.Close()
 	}
 }
 
@@ -149,46 +99,12 @@ func ById() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var db = storage.Get(constants.Persistence)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The provided ID parameter cannot be processed as integer",
-						},
-					},
-				},
-			})
-		}
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+			return err
+		}
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -197,75 +113,13 @@ func ById() echo.HandlerFunc {
 			}
 		}()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-
-		var q string
-		switch {
-		case constants.Persistence == storage.PostgreSQL:
-			q = "SELECT * FROM events WHERE id = $1 LIMIT 1;"
-		case constants.Persistence == storage.MySQL:
-			q = "SELECT * FROM events WHERE id = ? LIMIT 1;"
-		}
+		ctx := c.Request().Context()
 
-		stmt, err := db.PrepareContext(ctx, q)
+		event, err := repository.GetEvent(ctx, db, int64(id))
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return err
 		}
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
-
-		var event models.Event
-
-		err = stmt.QueryRowContext(ctx, id).Scan(&event.Id, &event.Name, &event.Created_at)
-		if err != nil {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "Event not found",
-						},
-					},
-				},
-			})
-		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.get",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-			Data: event,
-		})
+		return controllers.Entity(c, event.Version, event)
 	}
 }
 
@@ -275,47 +129,18 @@ func FetchTicketsById() echo.HandlerFunc {
 
 		desc, _ := strconv.ParseBool(c.QueryParam("desc"))
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
+		if err != nil {
+			return err
+		}
+
+		page, err := controllers.Page(c)
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID provided cannot be processed as integer",
-						},
-					},
-				},
-			})
-		}
-
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal server error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal server error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -324,67 +149,60 @@ func FetchTicketsById() echo.HandlerFunc {
 			}
 		}()
 
+		ctx := c.Request().Context()
+
 		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT EXISTS (SELECT 1 FROM events WHERE id = $1);"
+		case storage.MySQL:
+			q = "SELECT EXISTS (SELECT 1 FROM events WHERE id = ?);"
+		}
+
+		exists, err := controllers.Exists(ctx, db, q, id)
+		if err != nil {
+			return controllers.Internal("The event could not be checked", err)
+		}
+		if !exists {
+			return controllers.NotFound("Event not found")
+		}
+
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT COUNT(*) FROM tickets WHERE event = $1;"
+		case storage.MySQL:
+			q = "SELECT COUNT(*) FROM tickets WHERE event = ?;"
+		}
+
+		page.Total, err = controllers.Count(ctx, db, q, id)
+		if err != nil {
+			return controllers.Internal("The tickets could not be counted", err)
+		}
+
 		switch {
 		case constants.Persistence == storage.PostgreSQL && desc:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id DESC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id DESC LIMIT $2 OFFSET $3;"
 		case constants.Persistence == storage.MySQL && desc:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id DESC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id DESC LIMIT ? OFFSET ?;"
 		case constants.Persistence == storage.PostgreSQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id ASC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id ASC LIMIT $2 OFFSET $3;"
 		case constants.Persistence == storage.MySQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id ASC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id ASC LIMIT ? OFFSET ?;"
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
-			if err != nil {
+			if err = stmt.Close(); err != nil {
 				panic(err)
 			}
 		}()
 
-		rows, err := stmt.QueryContext(ctx, id)
+		rows, err := stmt.QueryContext(ctx, id, page.Limit, page.Offset)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal server error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "The ID provided was rejected, not valid",
-						},
-					},
-				},
-			})
+			return controllers.Internal("There was an error when tried to bring the payload", err)
 		}
 		defer func() {
 			if err = rows.Close(); err != nil {
@@ -392,52 +210,22 @@ func FetchTicketsById() echo.HandlerFunc {
 			}
 		}()
 
-		var tviews models.TicketViews
-
+		var list = controllers.NewList(c, page, "tickets", models.TicketView{})
 		for rows.Next() {
 			var tview models.TicketView
 
-			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event); err != nil {
-				return c.JSON(http.StatusConflict, models.BadResponse{
-					APIVersion: constants.APIVersion,
-					Method:     "events.get",
-					Context:    c.Request().URL.String(),
-					Params: map[string]interface{}{
-						"id": id,
-					},
-					Error: models.Error{
-						Code:    409,
-						Message: "Conflict",
-						Errors: []map[string]interface{}{
-							{
-								"reason":  "Conflict",
-								"message": "An error was logged while trying to process the payload",
-							},
-						},
-					},
-				})
+			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event, &tview.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = list.Add(tview); err != nil {
+				return err
 			}
-			tviews = append(tviews, tview)
-		}
-		if len(tviews) == 0 {
-			return c.JSON(http.StatusNoContent, models.SuccessfulResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-			})
-		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.get",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-			Data: tviews,
-		})
+		}
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
+		}
+
+		return list.Close()
 	}
 }
 
@@ -447,46 +235,18 @@ func FetchParticipantsById() echo.HandlerFunc {
 
 		desc, _ := strconv.ParseBool(c.QueryParam("desc"))
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
+		if err != nil {
+			return err
+		}
+
+		page, err := controllers.Page(c)
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable entity",
-							"message": "The ID provided cannot be processed as integer",
-						},
-					},
-				},
-			})
-		}
-
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -495,66 +255,60 @@ func FetchParticipantsById() echo.HandlerFunc {
 			}
 		}()
 
+		ctx := c.Request().Context()
+
 		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT EXISTS (SELECT 1 FROM events WHERE id = $1);"
+		case storage.MySQL:
+			q = "SELECT EXISTS (SELECT 1 FROM events WHERE id = ?);"
+		}
+
+		exists, err := controllers.Exists(ctx, db, q, id)
+		if err != nil {
+			return controllers.Internal("The event could not be checked", err)
+		}
+		if !exists {
+			return controllers.NotFound("Event not found")
+		}
+
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT COUNT(*) FROM tickets WHERE event = $1;"
+		case storage.MySQL:
+			q = "SELECT COUNT(*) FROM tickets WHERE event = ?;"
+		}
+
+		page.Total, err = controllers.Count(ctx, db, q, id)
+		if err != nil {
+			return controllers.Internal("The tickets could not be counted", err)
+		}
+
 		switch {
 		case constants.Persistence == storage.PostgreSQL && desc:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id DESC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id DESC LIMIT $2 OFFSET $3;"
 		case constants.Persistence == storage.MySQL && desc:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id DESC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id DESC LIMIT ? OFFSET ?;"
 		case constants.Persistence == storage.PostgreSQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id ASC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 ORDER BY t.id ASC LIMIT $2 OFFSET $3;"
 		case constants.Persistence == storage.MySQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id ASC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? ORDER BY t.id ASC LIMIT ? OFFSET ?;"
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
-			if err != nil {
+			if err = stmt.Close(); err != nil {
 				panic(err)
 			}
 		}()
-		rows, err := stmt.QueryContext(ctx, id)
+
+		rows, err := stmt.QueryContext(ctx, id, page.Limit, page.Offset)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The ID provided was rejected, not valid",
-						},
-					},
-				},
-			})
+			return controllers.Internal("There was an error when tried to bring the payload", err)
 		}
 		defer func() {
 			if err = rows.Close(); err != nil {
@@ -562,62 +316,22 @@ func FetchParticipantsById() echo.HandlerFunc {
 			}
 		}()
 
-		var tviews models.TicketViews
-
+		var list = controllers.NewList(c, page, "participants", models.TicketView{})
 		for rows.Next() {
 			var tview models.TicketView
 
-			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event); err != nil {
-				return c.JSON(http.StatusConflict, models.BadResponse{
-					APIVersion: constants.APIVersion,
-					Method:     "events.get",
-					Context:    c.Request().URL.String(),
-					Params: map[string]interface{}{
-						"id": id,
-					},
-					Error: models.Error{
-						Code:    409,
-						Message: "Conflict",
-						Errors: []map[string]interface{}{
-							{
-								"reason":  "Conflict",
-								"message": "An error was logged while trying to process the payload",
-							},
-						},
-					},
-				})
+			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event, &tview.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = list.Add(tview); err != nil {
+				return err
 			}
-			tviews = append(tviews, tview)
-		}
-		if len(tviews) == 0 {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Either the event does not exist or it has no participants",
-							"message": "Not found",
-						},
-					},
-				},
-			})
-		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.get",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-			Data: tviews,
-		})
+		}
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
+		}
+
+		return list.Close()
 	}
 }
 
@@ -625,72 +339,18 @@ func FetchParticipantByIds() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var db = storage.Get(constants.Persistence)
 
-		eventId, err := strconv.Atoi(c.Param("event-id"))
+		eventId, err := controllers.IntParam(c, "event-id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"event_id":       eventId,
-					"participant_id": 0,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The event ID parameter provided cannot be processed as integer",
-						},
-					},
-				},
-			})
-		}
-
-		participantId, err := strconv.Atoi(c.Param("participant-id"))
+			return err
+		}
+
+		participantId, err := controllers.IntParam(c, "participant-id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"event_id":       eventId,
-					"participant_id": participantId,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The participant ID parameter provided cannot be processed as integer",
-						},
-					},
-				},
-			})
-		}
-
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"event_id":       eventId,
-					"participant_id": participantId,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -702,34 +362,16 @@ func FetchParticipantByIds() echo.HandlerFunc {
 		var q string
 		switch constants.Persistence {
 		case storage.PostgreSQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 AND p.id = $2;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = $1 AND p.id = $2;"
 		case storage.MySQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? AND p.id = ?;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE e.id = ? AND p.id = ?;"
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
-		defer cancel()
+		ctx := c.Request().Context()
 
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"event_id":       eventId,
-					"participant_id": participantId,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal server error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
 			if err = stmt.Close(); err != nil {
@@ -738,38 +380,11 @@ func FetchParticipantByIds() echo.HandlerFunc {
 		}()
 
 		var tview models.TicketView
-		err = stmt.QueryRowContext(ctx, eventId, participantId).Scan(&tview.Id, &tview.Participant, &tview.Event)
+		err = stmt.QueryRowContext(ctx, eventId, participantId).Scan(&tview.Id, &tview.Participant, &tview.Event, &tview.Version)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"event_id":       eventId,
-					"participant_id": participantId,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "The event or participant was not found",
-						},
-					},
-				},
-			})
-		}
-
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.get",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"event_id":       eventId,
-				"participant_id": participantId,
-			},
-			Data: tview,
-		})
+			return controllers.Rejected(ctx, err, controllers.NotFound("The event or participant was not found"))
+		}
+
+		return controllers.Entity(c, tview.Version, tview)
 	}
 }
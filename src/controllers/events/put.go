@@ -1,15 +1,15 @@
 package events
 
 import (
-	"context"
+	"database/sql"
 	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
 	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
@@ -20,183 +20,48 @@ func UpdateById() echo.HandlerFunc {
 			request = new(models.Event)
 		)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID provided cannot be processed as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
 
 		if err = c.Bind(request); err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The request body data is not valid",
-						},
-					},
-				},
-			})
+			return controllers.BadRequest("The request body data is not valid")
 		}
 
 		if (*request == models.Event{}) {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The request body data is empty",
-						},
-					},
-				},
-			})
+			return controllers.BadRequest("The request body data is empty")
 		}
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal server error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal server error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
-
 		defer func() {
 			if err = db.Close(); err != nil {
 				panic(err)
 			}
 		}()
 
-		var q string
-		switch constants.Persistence {
-		case storage.PostgreSQL:
-			q = "UPDATE events SET name = $1 WHERE id = $2;"
-		case storage.MySQL:
-			q = "UPDATE events SET name = ? WHERE id = ?;"
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
 
-		stmt, err := db.PrepareContext(ctx, q)
+		version, err := controllers.Version(ctx, db, "events", id)
+		if err == sql.ErrNoRows {
+			return controllers.NotFound("Event not found")
+		}
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The event version could not be retrieved", err)
 		}
 
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
-		r, err := stmt.ExecContext(ctx, request.Name, id)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "Are you following any criteria for insertion?",
-						},
-					},
-				},
-			})
+		if err = controllers.CheckIfMatch(c, version); err != nil {
+			return err
 		}
 
-		if i, _ := r.RowsAffected(); i == 0 {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "events.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "Event not found",
-						},
-					},
-				},
-			})
+		event, err := repository.UpdateEvent(ctx, db, int64(id), *request, &version)
+		if err != nil {
+			return err
 		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "events.put",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-		})
+
+		controllers.SetETag(c, event.Version)
+		return controllers.Respond(c, http.StatusOK, nil)
 	}
 }
@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"errors"
+
+	"github.com/graph-gophers/graphql-go/types"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+)
+
+// Cost of the operation that would run, the fields it would resolve at
+// most: each field counts once per item of the lists it's in, a list is as
+// long as its first argument, or the limit of the page it's in, or the
+// default page. The variables without a value take their default one.
+// Documents that can't be read, or without the operation, are an error, a
+// query can't go unchecked.
+func Cost(query, operationName string, variables map[string]interface{}) (int, error) {
+	doc, err := parse(query)
+	if err != nil {
+		return 0, err
+	}
+
+	op := doc.operation(operationName)
+	if op == nil {
+		return 0, errNoOperation
+	}
+
+	var c = coster{schema: schema.ASTSchema(), doc: doc, op: op, variables: variables}
+	return c.selections(op.selections, c.schema.EntryPoints[op.kind], 1, 0, 0), nil
+}
+
+var errNoOperation = errors.New("the document has no such operation")
+
+type coster struct {
+	schema    *types.Schema
+	doc       *document
+	op        *operation
+	variables map[string]interface{}
+}
+
+// selections of a value of the type, in n items, page is the limit of the
+// page the value is, zero if it's not a page
+func (c *coster) selections(selections []selection, on types.NamedType, n, page, depth int) int {
+	var total int
+
+	// The fragments that spread themselves are rejected by graphql-go
+	if depth > constants.MaxGraphQLDepth*4 {
+		return total
+	}
+
+	for _, s := range selections {
+		if total > constants.MaxGraphQLCost {
+			break
+		}
+
+		switch {
+		case s.spread != "":
+			if f, ok := c.doc.fragments[s.spread]; ok {
+				total += c.selections(f.selections, c.schema.Types[f.on], n, page, depth+1)
+			}
+
+		case s.field == "":
+			var t = on
+			if s.on != "" {
+				t = c.schema.Types[s.on]
+			}
+			total += c.selections(s.selections, t, n, page, depth+1)
+
+		default:
+			total += n
+
+			object, ok := on.(*types.ObjectTypeDefinition)
+			if !ok || len(s.selections) == 0 {
+				continue
+			}
+			field := object.Fields.Get(s.field)
+			if field == nil {
+				continue
+			}
+
+			// The items of a page are as many as its limit
+			size, ok := c.size(field, s)
+			if !ok {
+				size, ok = page, page > 0
+			}
+
+			var items, next = n, 0
+			if ok && isList(field.Type) {
+				items = min(n*size, constants.MaxGraphQLCost+1)
+			} else if ok {
+				next = size
+			}
+			total += c.selections(s.selections, namedOf(field.Type), items, next, depth+1)
+		}
+	}
+	return total
+}
+
+// size of the list of the field, its limit or first argument, false if it
+// has none of them
+func (c *coster) size(field *types.FieldDefinition, s selection) (int, bool) {
+	for _, name := range []string{"limit", "first"} {
+		if field.Arguments.Get(name) == nil {
+			continue
+		}
+
+		var size = constants.DefaultPageLimit
+		if v, ok := s.arguments[name]; ok {
+			switch {
+			case v.int != nil:
+				size = *v.int
+			case v.variable != "":
+				size = c.variable(v.variable, size)
+			}
+		}
+		// Out of range is rejected by the resolvers
+		return max(1, min(size, constants.MaxPageLimit)), true
+	}
+	return 0, false
+}
+
+// variable of a size, its value, else its default one, else size. An
+// explicit null is no value, the resolvers take their default page.
+func (c *coster) variable(name string, size int) int {
+	if v, ok := c.variables[name]; ok {
+		if f, ok := v.(float64); ok {
+			return int(f)
+		}
+		return size
+	}
+	if d, ok := c.op.defaults[name]; ok && d.int != nil {
+		return *d.int
+	}
+	return size
+}
+
+func isList(t types.Type) bool {
+	if w, ok := t.(*types.NonNull); ok {
+		t = w.OfType
+	}
+	_, ok := t.(*types.List)
+	return ok
+}
+
+func namedOf(t types.Type) types.NamedType {
+	for {
+		switch w := t.(type) {
+		case *types.NonNull:
+			t = w.OfType
+		case *types.List:
+			t = w.OfType
+		case types.NamedType:
+			return w
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+)
+
+func TestCost(t *testing.T) {
+	const bypass = `query Q($n: Int = 1000) { events(limit: $n) { items { tickets(first: $n) { participant { id firstname } } } } }`
+
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]interface{}
+		want      int
+	}{
+		{name: "page fields", query: `{ events { total } }`, want: 2},
+		{name: "default page", query: `{ events { items { id } } }`, want: 102},
+		{name: "aliases", query: `{ a: events(limit: 10) { items { id } } b: events(limit: 10) { items { id } } }`, want: 24},
+		{name: "fragment", query: `{ events(limit: 10) { items { ...E } } } fragment E on Event { id tickets(first: 5) { id } }`, want: 72},
+		{name: "inline fragment", query: `{ event(id: 1) { ... on Event { participants(first: 3) { id } } } }`, want: 5},
+		{name: "nested first and limit", query: `{ events(limit: 1000) { items { participants(first: 1000) { id } } } }`, want: 51003},
+		{name: "variable", query: `query($n: Int) { events(limit: $n) { items { id } } }`, variables: map[string]interface{}{"n": float64(7)}, want: 9},
+		{name: "default value", query: `query($n: Int = 7) { events(limit: $n) { items { id } } }`, want: 9},
+		{name: "variable over its default", query: `query($n: Int = 1000) { events(limit: $n) { items { id } } }`, variables: map[string]interface{}{"n": float64(2)}, want: 4},
+		{name: "null variable", query: `query($n: Int = 7) { events(limit: $n) { items { id } } }`, variables: map[string]interface{}{"n": nil}, want: 102},
+		{name: "default values bypass", query: bypass, want: 101004},
+		{name: "variables bypass", query: bypass, variables: map[string]interface{}{"n": float64(1000)}, want: 101004},
+		{name: "named operation", query: `query A { events { total } } query B { events(limit: 5) { items { id } } }`, operation: "B", want: 7},
+		{name: "other values", query: `query($ids: [ID!]! = ["1"], $b: Boolean = true) { events(filter: {name: "a \"b\""}) @include(if: $b) { total } }`, want: 2},
+		{name: "mutation", query: `mutation { createEvent(input: {name: """x"""}) { id } }`, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Cost(tt.query, tt.operation, tt.variables)
+			if err != nil {
+				t.Fatalf("Cost(%q) failed: %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("Cost(%q) = %d, want %d", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCostOverTheLimit(t *testing.T) {
+	for _, query := range []string{
+		`query Q($n: Int = 1000) { events(limit: $n) { items { tickets(first: $n) { participant { id firstname } } } } }`,
+		`{ events(limit: 1000) { items { ...P } } } fragment P on Event { participants(first: 1000) { id } }`,
+		`{ events(limit: 1000) { items { ... on Event { tickets(first: 1000) { id } } } } }`,
+	} {
+		if got, err := Cost(query, "", nil); err != nil || got <= constants.MaxGraphQLCost {
+			t.Errorf("Cost(%q) = %d, %v, want more than %d", query, got, err, constants.MaxGraphQLCost)
+		}
+	}
+}
+
+func TestCostRejectsUnreadableDocuments(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+	}{
+		{name: "unclosed", query: `{ events {`},
+		{name: "extra brace", query: `{ events { total } } }`},
+		{name: "variable without type", query: `query($n) { events { total } }`},
+		{name: "missing argument value", query: `{ events(limit: ) { total } }`},
+		{name: "description", query: `"""doc""" { events { total } }`},
+		{name: "unknown character", query: `{ events { total } } &`},
+		{name: "two anonymous operations", query: `{ events { total } } { events { total } }`},
+		{name: "unknown operation", query: `query A { events { total } }`, operation: "B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Cost(tt.query, tt.operation, nil); err == nil {
+				t.Errorf("Cost(%q) = %d, want an error", tt.query, got)
+			}
+		})
+	}
+}
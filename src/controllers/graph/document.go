@@ -0,0 +1,457 @@
+package graph
+
+import (
+	"errors"
+	"strconv"
+)
+
+// document is what the rate limit and the cost limit need of a query: the
+// operations, the fragments, the fields and their Int arguments. It's read
+// before the query reaches graphql-go, which keeps its parser internal,
+// and rejects the documents that don't follow the grammar.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind, name string
+	defaults   map[string]value // Default values of the variables
+	selections []selection
+}
+
+type fragment struct {
+	on         string
+	selections []selection
+}
+
+// selection is a field, a fragment spread or an inline fragment
+type selection struct {
+	field      string
+	arguments  map[string]value
+	spread     string
+	on         string
+	selections []selection
+}
+
+// value of an argument, an Int or a variable, the others are left out
+type value struct {
+	int      *int
+	variable string
+}
+
+var errSyntax = errors.New("the document is not valid")
+
+// operation to run, the one named or else the only one, nil if there's
+// no such operation
+func (d *document) operation(name string) *operation {
+	for _, op := range d.operations {
+		if name != "" && op.name == name || name == "" && len(d.operations) == 1 {
+			return op
+		}
+	}
+	return nil
+}
+
+func parse(query string) (*document, error) {
+	var p = parser{lexer: lexer{src: query}, doc: &document{fragments: make(map[string]*fragment)}}
+	p.next()
+
+	for p.tok.kind != tokenEOF {
+		if err := p.definition(); err != nil {
+			return nil, err
+		}
+	}
+	return p.doc, nil
+}
+
+type parser struct {
+	lexer
+	tok token
+	doc *document
+}
+
+func (p *parser) next() { p.tok = p.lex() }
+
+func (p *parser) is(kind tokenKind, text string) bool {
+	return p.tok.kind == kind && p.tok.text == text
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if p.tok.kind != kind || text != "" && p.tok.text != text {
+		return errSyntax
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	var name = p.tok.text
+	return name, p.expect(tokenName, "")
+}
+
+func (p *parser) definition() error {
+	var err error
+
+	switch {
+	case p.is(tokenPunct, "{"):
+		var op = &operation{kind: "query"}
+		op.selections, err = p.selectionSet()
+		p.doc.operations = append(p.doc.operations, op)
+
+	case p.is(tokenName, "query"), p.is(tokenName, "mutation"), p.is(tokenName, "subscription"):
+		var op = &operation{kind: p.tok.text}
+		p.next()
+		if p.tok.kind == tokenName {
+			op.name = p.tok.text
+			p.next()
+		}
+		op.defaults, err = p.variables()
+		if err == nil {
+			err = p.directives()
+		}
+		if err == nil {
+			op.selections, err = p.selectionSet()
+		}
+		p.doc.operations = append(p.doc.operations, op)
+
+	case p.is(tokenName, "fragment"):
+		p.next()
+		var (
+			f    = new(fragment)
+			name string
+		)
+		if name, err = p.name(); err != nil {
+			return err
+		}
+		if err = p.expect(tokenName, "on"); err != nil {
+			return err
+		}
+		if f.on, err = p.name(); err != nil {
+			return err
+		}
+		if err = p.directives(); err != nil {
+			return err
+		}
+		f.selections, err = p.selectionSet()
+		p.doc.fragments[name] = f
+
+	default:
+		err = errSyntax
+	}
+	return err
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect(tokenPunct, "{"); err != nil {
+		return nil, err
+	}
+
+	var selections []selection
+	for !p.is(tokenPunct, "}") {
+		if p.tok.kind == tokenEOF {
+			return nil, errSyntax
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	p.next()
+
+	return selections, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	var (
+		s   selection
+		err error
+	)
+
+	if p.is(tokenPunct, "...") {
+		p.next()
+		if p.tok.kind == tokenName && p.tok.text != "on" {
+			s.spread = p.tok.text
+			p.next()
+			return s, p.directives()
+		}
+		if p.is(tokenName, "on") {
+			p.next()
+			if s.on, err = p.name(); err != nil {
+				return s, err
+			}
+		}
+		if err = p.directives(); err != nil {
+			return s, err
+		}
+		s.selections, err = p.selectionSet()
+		return s, err
+	}
+
+	if s.field, err = p.name(); err != nil {
+		return s, err
+	}
+	if p.is(tokenPunct, ":") {
+		p.next()
+		if s.field, err = p.name(); err != nil {
+			return s, err
+		}
+	}
+	if s.arguments, err = p.arguments(); err != nil {
+		return s, err
+	}
+	if err = p.directives(); err != nil {
+		return s, err
+	}
+	if p.is(tokenPunct, "{") {
+		s.selections, err = p.selectionSet()
+	}
+	return s, err
+}
+
+func (p *parser) arguments() (map[string]value, error) {
+	if !p.is(tokenPunct, "(") {
+		return nil, nil
+	}
+	p.next()
+
+	var arguments = make(map[string]value)
+	for !p.is(tokenPunct, ")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+		if arguments[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+
+	return arguments, nil
+}
+
+// variables definitions of an operation, e.g. ($n: Int = 10), their
+// default values by name
+func (p *parser) variables() (map[string]value, error) {
+	var defaults = make(map[string]value)
+	if !p.is(tokenPunct, "(") {
+		return defaults, nil
+	}
+	p.next()
+
+	for !p.is(tokenPunct, ")") {
+		if err := p.expect(tokenPunct, "$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+		if err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if p.is(tokenPunct, "=") {
+			p.next()
+			if defaults[name], err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.directives(); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+
+	return defaults, nil
+}
+
+// typeRef of a variable, e.g. [Int!]!
+func (p *parser) typeRef() error {
+	if p.is(tokenPunct, "[") {
+		p.next()
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if err := p.expect(tokenPunct, "]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+
+	if p.is(tokenPunct, "!") {
+		p.next()
+	}
+	return nil
+}
+
+func (p *parser) value() (value, error) {
+	var v value
+
+	switch {
+	case p.is(tokenPunct, "$"):
+		p.next()
+		name, err := p.name()
+		v.variable = name
+		return v, err
+	case p.tok.kind == tokenInt:
+		if n, err := strconv.Atoi(p.tok.text); err == nil {
+			v.int = &n
+		}
+	case p.is(tokenPunct, "["):
+		return v, p.skip("[", "]")
+	case p.is(tokenPunct, "{"):
+		return v, p.skip("{", "}")
+	case p.tok.kind == tokenPunct || p.tok.kind == tokenEOF:
+		return v, errSyntax
+	}
+	p.next()
+
+	return v, nil
+}
+
+func (p *parser) directives() error {
+	for p.is(tokenPunct, "@") {
+		p.next()
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if _, err := p.arguments(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip a balanced group, e.g. a list or an object value
+func (p *parser) skip(open, close string) error {
+	var depth int
+	for {
+		switch {
+		case p.tok.kind == tokenEOF:
+			return errSyntax
+		case p.is(tokenPunct, open):
+			depth++
+		case p.is(tokenPunct, close):
+			depth--
+		}
+		p.next()
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenOther // Floats and strings
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// lex returns the next token, the ignored ones, commas included, are
+// skipped. A character that can't start a token is a punctuator, the
+// parser rejects it.
+func (l *lexer) lex() token {
+	for l.pos < len(l.src) {
+		switch ch := l.src[l.pos]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == ',':
+			l.pos++
+		case ch == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return l.token()
+		}
+	}
+	return token{kind: tokenEOF}
+}
+
+func (l *lexer) token() token {
+	var (
+		start = l.pos
+		ch    = l.src[l.pos]
+	)
+
+	switch {
+	case ch == '"':
+		l.pos = skipString(l.src, l.pos)
+		return token{kind: tokenOther, text: l.src[start:l.pos]}
+
+	case ch == '.' && len(l.src) >= l.pos+3 && l.src[l.pos:l.pos+3] == "...":
+		l.pos += 3
+		return token{kind: tokenPunct, text: "..."}
+
+	case isNameStart(ch):
+		for l.pos++; l.pos < len(l.src) && isName(l.src[l.pos]); l.pos++ {
+		}
+		return token{kind: tokenName, text: l.src[start:l.pos]}
+
+	case ch == '-' || '0' <= ch && ch <= '9':
+		var kind = tokenInt
+		for l.pos++; l.pos < len(l.src); l.pos++ {
+			if c := l.src[l.pos]; c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-' {
+				kind = tokenOther
+			} else if c < '0' || c > '9' {
+				break
+			}
+		}
+		return token{kind: kind, text: l.src[start:l.pos]}
+	}
+
+	l.pos++
+	return token{kind: tokenPunct, text: l.src[start:l.pos]}
+}
+
+// skipString returns the index after the string or block string at i
+func skipString(s string, i int) int {
+	if len(s) >= i+3 && s[i:i+3] == `"""` {
+		for i += 3; i < len(s); i++ {
+			if s[i] == '\\' && len(s) >= i+4 && s[i+1:i+4] == `"""` {
+				i += 3
+			} else if len(s) >= i+3 && s[i:i+3] == `"""` {
+				return i + 3
+			}
+		}
+		return i
+	}
+
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"', '\n':
+			return i + 1
+		}
+	}
+	return i
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+}
+
+func isName(ch byte) bool {
+	return isNameStart(ch) || '0' <= ch && ch <= '9'
+}
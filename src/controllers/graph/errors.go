@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// resolverError carries the status the REST route would respond with in
+// the extensions of the GraphQL error, e.g.
+//
+//	{"message": "Event not found", "extensions": {"code": "NOT_FOUND", "status": 404}}
+type resolverError struct {
+	err    error
+	status int
+	detail string
+	fields []models.FieldError
+}
+
+// fail wraps the errors of the controllers package, the message is the
+// detail only, the cause of internal errors is never exposed
+func fail(err error) error {
+	status, detail, fields := controllers.StatusOf(err)
+	return &resolverError{err: err, status: status, detail: detail, fields: fields}
+}
+
+func (e *resolverError) Error() string { return e.detail }
+func (e *resolverError) Unwrap() error { return e.err }
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	var ext = map[string]interface{}{
+		"code":   strings.ToUpper(strings.ReplaceAll(http.StatusText(e.status), " ", "_")),
+		"status": e.status,
+	}
+	if len(e.fields) > 0 {
+		ext["errors"] = e.fields
+	}
+	return ext
+}
+
+func isNotFound(err error) bool {
+	var notFound *controllers.NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// parseID of an argument, IDs are the same integers as in the REST routes
+func parseID(v graphql.ID, name string) (int64, error) {
+	n, err := strconv.ParseInt(string(v), 10, 64)
+	if err != nil || n < 1 {
+		return 0, fail(controllers.Invalid("The "+name+" argument is not a valid ID", models.FieldError{
+			Field:   name,
+			Message: "must be a positive integer",
+		}))
+	}
+	return n, nil
+}
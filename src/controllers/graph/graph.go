@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+//go:embed schema.graphql
+var sdl string
+
+// Parsed when the server starts, a resolver that doesn't match the schema
+// stops it
+var schema = graphql.MustParseSchema(sdl, &Resolver{},
+	graphql.MaxDepth(constants.MaxGraphQLDepth),
+	graphql.MaxParallelism(constants.MaxGraphQLParallelism),
+)
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query runs the GraphQL request of the body, the errors of the resolvers
+// are in the response with a 200 as the GraphQL over HTTP spec says
+func Query() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			db      = storage.Get(constants.Persistence)
+			request = new(request)
+			err     error
+		)
+
+		if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+			return controllers.UnsupportedMediaType("The request body must be application/json")
+		}
+
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, constants.MaxGraphQLBodySize)
+
+		var tooLarge *http.MaxBytesError
+		if err = c.Bind(request); errors.As(err, &tooLarge) {
+			return controllers.TooLarge("The request body is over " + strconv.FormatInt(constants.MaxGraphQLBodySize>>10, 10) + " KiB")
+		}
+		if err != nil {
+			return controllers.BadRequest("The request body data is not valid")
+		}
+
+		if strings.TrimSpace(request.Query) == "" {
+			return controllers.BadRequest("The query is required")
+		}
+
+		// Rejected before the database is reached, the same way graphql-go
+		// rejects the queries that are too deep
+		cost, err := Cost(request.Query, request.OperationName, request.Variables)
+		if err != nil {
+			return c.JSON(http.StatusOK, &graphql.Response{Errors: []*gqlerrors.QueryError{{
+				Message: "The query is not a valid GraphQL document, or it has no such operation",
+				Extensions: map[string]interface{}{
+					"code":   "BAD_REQUEST",
+					"status": http.StatusBadRequest,
+				},
+			}}})
+		}
+		if cost > constants.MaxGraphQLCost {
+			return c.JSON(http.StatusOK, &graphql.Response{Errors: []*gqlerrors.QueryError{{
+				Message: "The query would resolve about " + strconv.Itoa(cost) + " fields, at most " + strconv.Itoa(constants.MaxGraphQLCost) + ", ask for fewer items with limit or first",
+				Extensions: map[string]interface{}{
+					"code":    "BAD_REQUEST",
+					"status":  http.StatusBadRequest,
+					"cost":    cost,
+					"maxCost": constants.MaxGraphQLCost,
+				},
+			}}})
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		ctx := c.Request().Context()
+
+		ctx = withLoaders(context.WithValue(ctx, dbKey{}, db), db)
+
+		res := schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+
+		for _, e := range res.Errors {
+			var internal *controllers.InternalError
+			if errors.As(e.ResolverError, &internal) {
+				slog.ErrorContext(ctx, "The GraphQL resolver failed", "path", e.Path, "error", internal)
+			}
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+type dbKey struct{}
+
+// dbOf is the connection of the request, shared by its resolvers
+func dbOf(ctx context.Context) database.Connecter {
+	return ctx.Value(dbKey{}).(database.Connecter)
+}
@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+)
+
+// loader fetches rows by key in batches, a single query per batch instead
+// of one per parent (N+1). The lists prime the keys of their items and
+// the rows of a batch prime the keys of their relations, so the first
+// load of a relation brings those of every item.
+type loader struct {
+	fetching sync.Mutex // Held while a batch is fetched, the loads wait for it
+	mu       sync.Mutex // Guards the keys, priming never waits for a batch
+	fetch    func(ctx context.Context, keys []int64) (map[int64]interface{}, error)
+	pending  []int64
+	queued   map[int64]bool
+	done     map[int64]result
+}
+
+type result struct {
+	value interface{}
+	err   error
+}
+
+func newLoader(fetch func(context.Context, []int64) (map[int64]interface{}, error)) *loader {
+	return &loader{fetch: fetch, queued: make(map[int64]bool), done: make(map[int64]result)}
+}
+
+// prime queues keys for the next batch without fetching them
+func (l *loader) prime(keys ...int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, k := range keys {
+		if _, ok := l.done[k]; !ok && !l.queued[k] {
+			l.queued[k] = true
+			l.pending = append(l.pending, k)
+		}
+	}
+}
+
+// load returns the value of a key, nil if there is no row for it. The
+// concurrent loads wait for the batch in flight, which includes them if
+// they were primed.
+func (l *loader) load(ctx context.Context, key int64) (interface{}, error) {
+	l.fetching.Lock()
+	defer l.fetching.Unlock()
+
+	l.mu.Lock()
+	if r, ok := l.done[key]; ok {
+		l.mu.Unlock()
+		return r.value, r.err
+	}
+
+	var batch = l.pending
+	if !l.queued[key] {
+		batch = append(batch, key)
+	}
+	l.pending, l.queued = nil, make(map[int64]bool)
+	l.mu.Unlock()
+
+	values, err := l.fetch(ctx, batch)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, k := range batch {
+		l.done[k] = result{value: values[k], err: err}
+	}
+	r := l.done[key]
+	return r.value, r.err
+}
+
+// loaders of a request, the cache lives as long as the request
+type loaders struct {
+	events               *loader
+	participants         *loader
+	ticketsByEvent       *loader
+	ticketsByParticipant *loader
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, db database.Connecter) context.Context {
+	var l = new(loaders)
+	l.events = newLoader(fetchEvents(db, l))
+	l.participants = newLoader(fetchParticipants(db, l))
+	l.ticketsByEvent = newLoader(fetchTickets(db, l, repository.TicketsOfEvents, func(t models.Ticket) int64 { return int64(t.Event) }))
+	l.ticketsByParticipant = newLoader(fetchTickets(db, l, repository.TicketsOfParticipants, func(t models.Ticket) int64 { return int64(t.Participant) }))
+
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersOf(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func fetchEvents(db database.Connecter, l *loaders) func(context.Context, []int64) (map[int64]interface{}, error) {
+	return func(ctx context.Context, ids []int64) (map[int64]interface{}, error) {
+		events, err := repository.EventsByID(ctx, db, ids)
+
+		var values = make(map[int64]interface{}, len(events))
+		for _, e := range events {
+			values[int64(e.Id)] = e
+			l.ticketsByEvent.prime(int64(e.Id))
+		}
+		return values, err
+	}
+}
+
+func fetchParticipants(db database.Connecter, l *loaders) func(context.Context, []int64) (map[int64]interface{}, error) {
+	return func(ctx context.Context, ids []int64) (map[int64]interface{}, error) {
+		participants, err := repository.ParticipantsByID(ctx, db, ids)
+
+		var values = make(map[int64]interface{}, len(participants))
+		for _, p := range participants {
+			values[int64(p.Id)] = p
+			l.ticketsByParticipant.prime(int64(p.Id))
+		}
+		return values, err
+	}
+}
+
+// fetchTickets groups the tickets by key, each ID gets a []models.Ticket,
+// empty if it has none
+func fetchTickets(db database.Connecter, l *loaders, fetch func(context.Context, database.Connecter, []int64) ([]models.Ticket, error), key func(models.Ticket) int64) func(context.Context, []int64) (map[int64]interface{}, error) {
+	return func(ctx context.Context, ids []int64) (map[int64]interface{}, error) {
+		tickets, err := fetch(ctx, db, ids)
+
+		var groups = make(map[int64][]models.Ticket, len(ids))
+		for _, t := range tickets {
+			groups[key(t)] = append(groups[key(t)], t)
+			l.events.prime(int64(t.Event))
+			l.participants.prime(int64(t.Participant))
+		}
+
+		var values = make(map[int64]interface{}, len(ids))
+		for _, id := range ids {
+			values[id] = groups[id]
+		}
+		return values, err
+	}
+}
@@ -0,0 +1,221 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+)
+
+type eventInput struct {
+	Name     string
+	StartsAt *graphql.Time
+	EndsAt   *graphql.Time
+}
+
+func (in eventInput) event() models.Event {
+	return models.Event{Name: in.Name, Starts_at: timeOf(in.StartsAt), Ends_at: timeOf(in.EndsAt)}
+}
+
+type participantInput struct {
+	Firstname string
+	Lastname  *string
+	Age       int32
+}
+
+// Ages out of the range of the column are left to Validate
+func (in participantInput) participant() models.Participant {
+	var p = models.Participant{Firstname: in.Firstname}
+	if in.Lastname != nil {
+		p.Lastname = *in.Lastname
+	}
+	if in.Age > 0 && in.Age < 256 {
+		p.Age = uint8(in.Age)
+	}
+	return p
+}
+
+type ticketInput struct {
+	Event       graphql.ID
+	Participant graphql.ID
+}
+
+func (in ticketInput) ids() (int64, int64, error) {
+	event, err := parseID(in.Event, "input.event")
+	if err != nil {
+		return 0, 0, err
+	}
+	participant, err := parseID(in.Participant, "input.participant")
+	return event, participant, err
+}
+
+func (*Resolver) CreateEvent(ctx context.Context, args struct{ Input eventInput }) (*eventResolver, error) {
+	if err := authorize(ctx, auth.CreateEvents); err != nil {
+		return nil, err
+	}
+
+	event, err := repository.CreateEvent(ctx, dbOf(ctx), args.Input.event())
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &eventResolver{event}, nil
+}
+
+func (*Resolver) UpdateEvent(ctx context.Context, args struct {
+	ID      graphql.ID
+	Input   eventInput
+	Version *int32
+}) (*eventResolver, error) {
+	id, err := parseID(args.ID, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, auth.ManageEvents, repository.OwnEvent(id)); err != nil {
+		return nil, err
+	}
+
+	event, err := repository.UpdateEvent(ctx, dbOf(ctx), id, args.Input.event(), versionOf(args.Version))
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &eventResolver{event}, nil
+}
+
+// The tickets of the event are deleted with it, the participants are kept
+func (*Resolver) DeleteEvent(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version *int32
+}) (graphql.ID, error) {
+	return remove(ctx, args.ID, args.Version, auth.ManageEvents, repository.OwnEvent, repository.DeleteEvent)
+}
+
+func (*Resolver) CreateParticipant(ctx context.Context, args struct{ Input participantInput }) (*participantResolver, error) {
+	if err := authorize(ctx, auth.CreateParticipants); err != nil {
+		return nil, err
+	}
+
+	participant, err := repository.CreateParticipant(ctx, dbOf(ctx), args.Input.participant())
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &participantResolver{participant}, nil
+}
+
+func (*Resolver) UpdateParticipant(ctx context.Context, args struct {
+	ID      graphql.ID
+	Input   participantInput
+	Version *int32
+}) (*participantResolver, error) {
+	id, err := parseID(args.ID, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, auth.ManageParticipants, repository.OwnParticipant(id)); err != nil {
+		return nil, err
+	}
+
+	participant, err := repository.UpdateParticipant(ctx, dbOf(ctx), id, args.Input.participant(), versionOf(args.Version))
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &participantResolver{participant}, nil
+}
+
+// The tickets of the participant are deleted with it
+func (*Resolver) DeleteParticipant(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version *int32
+}) (graphql.ID, error) {
+	return remove(ctx, args.ID, args.Version, auth.ManageParticipants, repository.OwnParticipant, repository.DeleteParticipant)
+}
+
+func (*Resolver) CreateTicket(ctx context.Context, args struct{ Input ticketInput }) (*ticketResolver, error) {
+	event, participant, err := args.Input.ids()
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, auth.RegisterTickets, repository.OwnEvent(event)); err != nil {
+		return nil, err
+	}
+
+	ticket, err := repository.CreateTicket(ctx, dbOf(ctx), event, participant)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return newTickets(ctx, []models.Ticket{ticket})[0], nil
+}
+
+func (*Resolver) UpdateTicket(ctx context.Context, args struct {
+	ID      graphql.ID
+	Input   ticketInput
+	Version *int32
+}) (*ticketResolver, error) {
+	id, err := parseID(args.ID, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	event, participant, err := args.Input.ids()
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, auth.ManageTickets, repository.OwnTicket(id), repository.OwnEvent(event)); err != nil {
+		return nil, err
+	}
+
+	ticket, err := repository.UpdateTicket(ctx, dbOf(ctx), id, event, participant, versionOf(args.Version))
+	if err != nil {
+		return nil, fail(err)
+	}
+	return newTickets(ctx, []models.Ticket{ticket})[0], nil
+}
+
+func (*Resolver) DeleteTicket(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version *int32
+}) (graphql.ID, error) {
+	return remove(ctx, args.ID, args.Version, auth.ManageTickets, repository.OwnTicket, repository.DeleteTicket)
+}
+
+// authorize the mutation for the principal of the request, the same
+// permissions as the REST routes
+func authorize(ctx context.Context, permission auth.Permission, checks ...repository.Check) error {
+	if err := repository.Authorize(ctx, dbOf(ctx), permission, checks...); err != nil {
+		return fail(err)
+	}
+	return nil
+}
+
+// remove parses the ID, authorizes it and deletes the row with one of the
+// Delete functions of the repository, the ID is returned back
+func remove(ctx context.Context, v graphql.ID, version *int32, permission auth.Permission, own func(int64) repository.Check,
+	del func(context.Context, database.Preparer, int64, *uint32) error) (graphql.ID, error) {
+	id, err := parseID(v, "id")
+	if err != nil {
+		return "", err
+	}
+	if err = authorize(ctx, permission, own(id)); err != nil {
+		return "", err
+	}
+	if err = del(ctx, dbOf(ctx), id, versionOf(version)); err != nil {
+		return "", fail(err)
+	}
+	return v, nil
+}
+
+// versionOf the argument as the repository expects it, negative versions
+// never match
+func versionOf(v *int32) *uint32 {
+	if v == nil {
+		return nil
+	}
+	var n uint32
+	if *v > 0 {
+		n = uint32(*v)
+	}
+	return &n
+}
@@ -0,0 +1,15 @@
+package graph
+
+// Mutates tells if the operation that would run, the one named
+// operationName or else the only one of the document, is a mutation. The
+// documents it can't tell about are mutations, so they are charged as
+// writes.
+func Mutates(query, operationName string) bool {
+	doc, err := parse(query)
+	if err != nil {
+		return true
+	}
+
+	op := doc.operation(operationName)
+	return op == nil || op.kind == "mutation"
+}
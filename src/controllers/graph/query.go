@@ -0,0 +1,200 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+)
+
+// Resolver is the root of the schema, queries and mutations
+type Resolver struct{}
+
+type pageArgs struct {
+	Limit  *int32
+	Offset *int32
+	Desc   *bool
+}
+
+func (a pageArgs) page() (models.Pagination, bool, error) {
+	var limit, offset int
+	if a.Limit != nil {
+		limit = int(*a.Limit)
+	}
+	if a.Offset != nil {
+		offset = int(*a.Offset)
+	}
+
+	page, err := repository.Page(limit, offset)
+	if err != nil {
+		return page, false, fail(err)
+	}
+	return page, a.Desc != nil && *a.Desc, nil
+}
+
+type pageResolver struct {
+	page models.Pagination
+}
+
+func (r pageResolver) Limit() int32  { return int32(r.page.Limit) }
+func (r pageResolver) Offset() int32 { return int32(r.page.Offset) }
+func (r pageResolver) Total() int32  { return int32(r.page.Total) }
+
+type eventPageResolver struct {
+	pageResolver
+	items []*eventResolver
+}
+
+func (r *eventPageResolver) Items() []*eventResolver { return r.items }
+
+type participantPageResolver struct {
+	pageResolver
+	items []*participantResolver
+}
+
+func (r *participantPageResolver) Items() []*participantResolver { return r.items }
+
+type ticketPageResolver struct {
+	pageResolver
+	items []*ticketResolver
+}
+
+func (r *ticketPageResolver) Items() []*ticketResolver { return r.items }
+
+func (*Resolver) Event(ctx context.Context, args struct{ ID graphql.ID }) (*eventResolver, error) {
+	id, err := parseID(args.ID, "id")
+	if err != nil {
+		return nil, err
+	}
+	return loadEvent(ctx, id)
+}
+
+func (*Resolver) Events(ctx context.Context, args struct {
+	Filter *struct {
+		Name         *string
+		Scheduled    *bool
+		StartsAfter  *graphql.Time
+		StartsBefore *graphql.Time
+	}
+	pageArgs
+}) (*eventPageResolver, error) {
+	page, desc, err := args.page()
+	if err != nil {
+		return nil, err
+	}
+
+	var filter repository.EventFilter
+	if f := args.Filter; f != nil {
+		filter = repository.EventFilter{Name: f.Name, Scheduled: f.Scheduled, StartsAfter: timeOf(f.StartsAfter), StartsBefore: timeOf(f.StartsBefore)}
+	}
+
+	events, err := repository.ListEvents(ctx, dbOf(ctx), filter, &page, desc)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &eventPageResolver{pageResolver{page}, newEvents(ctx, events)}, nil
+}
+
+func (*Resolver) Participant(ctx context.Context, args struct{ ID graphql.ID }) (*participantResolver, error) {
+	id, err := parseID(args.ID, "id")
+	if err != nil {
+		return nil, err
+	}
+	return loadParticipant(ctx, id)
+}
+
+func (*Resolver) Participants(ctx context.Context, args struct {
+	Filter *struct {
+		Name   *string
+		MinAge *int32
+		MaxAge *int32
+	}
+	pageArgs
+}) (*participantPageResolver, error) {
+	page, desc, err := args.page()
+	if err != nil {
+		return nil, err
+	}
+
+	var filter repository.ParticipantFilter
+	if f := args.Filter; f != nil {
+		filter = repository.ParticipantFilter{Name: f.Name, MinAge: intOf(f.MinAge), MaxAge: intOf(f.MaxAge)}
+	}
+
+	participants, err := repository.ListParticipants(ctx, dbOf(ctx), filter, &page, desc)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &participantPageResolver{pageResolver{page}, newParticipants(ctx, participants)}, nil
+}
+
+func (*Resolver) Ticket(ctx context.Context, args struct{ ID graphql.ID }) (*ticketResolver, error) {
+	id, err := parseID(args.ID, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := repository.GetTicket(ctx, dbOf(ctx), id)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fail(err)
+	}
+	return newTickets(ctx, []models.Ticket{ticket})[0], nil
+}
+
+func (*Resolver) Tickets(ctx context.Context, args struct {
+	Filter *struct {
+		Event       *graphql.ID
+		Participant *graphql.ID
+	}
+	pageArgs
+}) (*ticketPageResolver, error) {
+	page, desc, err := args.page()
+	if err != nil {
+		return nil, err
+	}
+
+	var filter repository.TicketFilter
+	if f := args.Filter; f != nil {
+		if f.Event != nil {
+			id, err := parseID(*f.Event, "filter.event")
+			if err != nil {
+				return nil, err
+			}
+			filter.Event = &id
+		}
+		if f.Participant != nil {
+			id, err := parseID(*f.Participant, "filter.participant")
+			if err != nil {
+				return nil, err
+			}
+			filter.Participant = &id
+		}
+	}
+
+	tickets, err := repository.ListTickets(ctx, dbOf(ctx), filter, &page, desc)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &ticketPageResolver{pageResolver{page}, newTickets(ctx, tickets)}, nil
+}
+
+func timeOf(t *graphql.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
+
+func intOf(n *int32) *int {
+	if n == nil {
+		return nil
+	}
+	var v = int(*n)
+	return &v
+}
@@ -0,0 +1,267 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+type eventResolver struct {
+	e models.Event
+}
+
+func (r *eventResolver) ID() graphql.ID          { return toID(uint64(r.e.Id)) }
+func (r *eventResolver) Name() string            { return r.e.Name }
+func (r *eventResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.e.Created_at} }
+func (r *eventResolver) StartsAt() *graphql.Time { return optionalTime(r.e.Starts_at) }
+func (r *eventResolver) EndsAt() *graphql.Time   { return optionalTime(r.e.Ends_at) }
+func (r *eventResolver) Version() int32          { return int32(r.e.Version) }
+
+func (r *eventResolver) Tickets(ctx context.Context, args relationArgs) ([]*ticketResolver, error) {
+	tickets, err := ticketsOf(ctx, loadersOf(ctx).ticketsByEvent, int64(r.e.Id))
+	if err != nil {
+		return nil, err
+	}
+	if tickets, err = args.window(tickets, ticketID); err != nil {
+		return nil, err
+	}
+	return newTickets(ctx, tickets), nil
+}
+
+func (r *eventResolver) Participants(ctx context.Context, args relationArgs) ([]*participantResolver, error) {
+	var l = loadersOf(ctx)
+
+	tickets, err := ticketsOf(ctx, l.ticketsByEvent, int64(r.e.Id))
+	if err != nil {
+		return nil, err
+	}
+	if tickets, err = args.window(tickets, participantID); err != nil {
+		return nil, err
+	}
+
+	var ids = make([]int64, len(tickets))
+	for i, t := range tickets {
+		ids[i] = int64(t.Participant)
+	}
+	l.participants.prime(ids...)
+
+	var participants = make([]*participantResolver, 0, len(ids))
+	for _, id := range ids {
+		p, err := loadParticipant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			participants = append(participants, p)
+		}
+	}
+	return participants, nil
+}
+
+type participantResolver struct {
+	p models.Participant
+}
+
+func (r *participantResolver) ID() graphql.ID    { return toID(r.p.Id) }
+func (r *participantResolver) Firstname() string { return r.p.Firstname }
+func (r *participantResolver) Lastname() string  { return r.p.Lastname }
+func (r *participantResolver) Age() int32        { return int32(r.p.Age) }
+func (r *participantResolver) Version() int32    { return int32(r.p.Version) }
+
+func (r *participantResolver) Tickets(ctx context.Context, args relationArgs) ([]*ticketResolver, error) {
+	tickets, err := ticketsOf(ctx, loadersOf(ctx).ticketsByParticipant, int64(r.p.Id))
+	if err != nil {
+		return nil, err
+	}
+	if tickets, err = args.window(tickets, ticketID); err != nil {
+		return nil, err
+	}
+	return newTickets(ctx, tickets), nil
+}
+
+func (r *participantResolver) Events(ctx context.Context, args relationArgs) ([]*eventResolver, error) {
+	var l = loadersOf(ctx)
+
+	tickets, err := ticketsOf(ctx, l.ticketsByParticipant, int64(r.p.Id))
+	if err != nil {
+		return nil, err
+	}
+	if tickets, err = args.window(tickets, eventID); err != nil {
+		return nil, err
+	}
+
+	var ids = make([]int64, len(tickets))
+	for i, t := range tickets {
+		ids[i] = int64(t.Event)
+	}
+	l.events.prime(ids...)
+
+	var events = make([]*eventResolver, 0, len(ids))
+	for _, id := range ids {
+		e, err := loadEvent(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+type ticketResolver struct {
+	t models.Ticket
+}
+
+func (r *ticketResolver) ID() graphql.ID { return toID(uint64(r.t.Id)) }
+func (r *ticketResolver) Version() int32 { return int32(r.t.Version) }
+
+// The foreign keys guarantee both of them, a missing one is a broken row
+func (r *ticketResolver) Event(ctx context.Context) (*eventResolver, error) {
+	e, err := loadEvent(ctx, int64(r.t.Event))
+	if err == nil && e == nil {
+		err = fail(controllers.Internal("The event of the ticket "+strconv.Itoa(int(r.t.Id))+" was not found", nil))
+	}
+	return e, err
+}
+
+func (r *ticketResolver) Participant(ctx context.Context) (*participantResolver, error) {
+	p, err := loadParticipant(ctx, int64(r.t.Participant))
+	if err == nil && p == nil {
+		err = fail(controllers.Internal("The participant of the ticket "+strconv.Itoa(int(r.t.Id))+" was not found", nil))
+	}
+	return p, err
+}
+
+// relationArgs of the lists of an event or a participant, they are in the
+// order of their tickets
+type relationArgs struct {
+	First *int32
+	After *graphql.ID
+}
+
+func ticketID(t models.Ticket) int64      { return int64(t.Id) }
+func eventID(t models.Ticket) int64       { return int64(t.Event) }
+func participantID(t models.Ticket) int64 { return int64(t.Participant) }
+
+// window of the tickets, the first ones after the one whose item, given by
+// id, has the ID of the after argument
+func (a relationArgs) window(tickets []models.Ticket, id func(models.Ticket) int64) ([]models.Ticket, error) {
+	var first = constants.DefaultPageLimit
+	if a.First != nil {
+		first = int(*a.First)
+		if first < 1 || first > constants.MaxPageLimit {
+			return nil, fail(controllers.Invalid("The first argument is not valid", models.FieldError{
+				Field:   "first",
+				Message: "must be an integer between 1 and " + strconv.Itoa(constants.MaxPageLimit),
+			}))
+		}
+	}
+
+	if a.After != nil {
+		after, err := parseID(*a.After, "after")
+		if err != nil {
+			return nil, err
+		}
+
+		var i = 0
+		for i < len(tickets) && id(tickets[i]) != after {
+			i++
+		}
+		if i == len(tickets) {
+			return nil, fail(controllers.Invalid("The after argument is not in the list", models.FieldError{
+				Field:   "after",
+				Message: "must be the ID of an item of the list",
+			}))
+		}
+		tickets = tickets[i+1:]
+	}
+
+	return tickets[:min(first, len(tickets))], nil
+}
+
+// Lists of items prime the relations of all of them, see loader
+
+func newEvents(ctx context.Context, events []models.Event) []*eventResolver {
+	var (
+		resolvers = make([]*eventResolver, len(events))
+		ids       = make([]int64, len(events))
+	)
+	for i, e := range events {
+		resolvers[i], ids[i] = &eventResolver{e}, int64(e.Id)
+	}
+	loadersOf(ctx).ticketsByEvent.prime(ids...)
+	return resolvers
+}
+
+func newParticipants(ctx context.Context, participants []models.Participant) []*participantResolver {
+	var (
+		resolvers = make([]*participantResolver, len(participants))
+		ids       = make([]int64, len(participants))
+	)
+	for i, p := range participants {
+		resolvers[i], ids[i] = &participantResolver{p}, int64(p.Id)
+	}
+	loadersOf(ctx).ticketsByParticipant.prime(ids...)
+	return resolvers
+}
+
+func newTickets(ctx context.Context, tickets []models.Ticket) []*ticketResolver {
+	var (
+		l         = loadersOf(ctx)
+		resolvers = make([]*ticketResolver, len(tickets))
+	)
+	for i, t := range tickets {
+		resolvers[i] = &ticketResolver{t}
+		l.events.prime(int64(t.Event))
+		l.participants.prime(int64(t.Participant))
+	}
+	return resolvers
+}
+
+func loadEvent(ctx context.Context, id int64) (*eventResolver, error) {
+	v, err := loadersOf(ctx).events.load(ctx, id)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return &eventResolver{v.(models.Event)}, nil
+}
+
+func loadParticipant(ctx context.Context, id int64) (*participantResolver, error) {
+	v, err := loadersOf(ctx).participants.load(ctx, id)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return &participantResolver{v.(models.Participant)}, nil
+}
+
+func ticketsOf(ctx context.Context, l *loader, id int64) ([]models.Ticket, error) {
+	v, err := l.load(ctx, id)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return v.([]models.Ticket), nil
+}
+
+func toID(n uint64) graphql.ID {
+	return graphql.ID(strconv.FormatUint(n, 10))
+}
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
@@ -0,0 +1,125 @@
+// Package health answers the probes of the load balancers and the
+// orchestrators, none of its routes needs credentials.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// The tables and views of the last schema of POST /persistence/build, with
+// the columns the handlers use. A database built before some of them were
+// added isn't ready, it must be built again.
+var schema = []struct{ table, columns string }{
+	{"events", "id, name, created_at, starts_at, ends_at, version"},
+	{"participants", "id, firstname, lastname, age, version"},
+	{"tickets", "id, event, participant, version"},
+	{"tickets_view", "id, participant, event, version"},
+	{"idempotency_keys", "principal, idempotency_key, request_hash, status, headers, body, created_at, expires_at"},
+	{"api_keys", "id, name, key_hash, role, created_at, revoked_at"},
+	{"event_organizers", "event, subject"},
+}
+
+// Live answers while the process serves requests, it doesn't look at the
+// database so a restart isn't the answer to an outage of it
+func Live() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, models.Health{Status: "ok"})
+	}
+}
+
+// Ready answers 503 until the database is reachable and has the schema,
+// within the deadline of the route. The details of the failures are
+// logged, not sent.
+func Ready() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			health = models.Health{Status: "ready", Checks: map[string]string{"database": "ok", "schema": "ok"}}
+			db     = storage.Get(constants.Persistence)
+			ctx    = c.Request().Context()
+		)
+
+		if err := db.Connect(ctx); err != nil {
+			slog.WarnContext(ctx, "The database is not reachable", "error", err)
+			health.Status, health.Checks["database"], health.Checks["schema"] = "unavailable", "unreachable", "unknown"
+			return c.JSON(http.StatusServiceUnavailable, health)
+		}
+
+		defer func() {
+			if err := db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		if table, err := checkSchema(ctx, db); err != nil {
+			slog.WarnContext(ctx, "The schema of the database is not the last one", "table", table, "error", err)
+			health.Status, health.Checks["schema"] = "unavailable", "missing or outdated "+table
+			return c.JSON(http.StatusServiceUnavailable, health)
+		}
+
+		return c.JSON(http.StatusOK, health)
+	}
+}
+
+// checkSchema selects no rows of each table, the first one the query fails
+// for is returned
+func checkSchema(ctx context.Context, db database.Connecter) (string, error) {
+	for _, t := range schema {
+		stmt, err := db.PrepareContext(ctx, "SELECT "+t.columns+" FROM "+t.table+" WHERE 1 = 0;")
+		if err != nil {
+			return t.table, err
+		}
+
+		rows, err := stmt.QueryContext(ctx)
+		if err == nil {
+			err = rows.Close()
+		}
+		stmt.Close()
+
+		if err != nil {
+			return t.table, err
+		}
+	}
+	return "", nil
+}
+
+// Version of the API and of the build, the commit and its time come from
+// the -ldflags or else from the VCS information go build embeds
+func Version() echo.HandlerFunc {
+	var v = models.Version{
+		APIVersion:  constants.APIVersion,
+		Commit:      constants.Commit,
+		BuildTime:   constants.BuildTime,
+		Persistence: constants.Persistence.String(),
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && v.Commit == "":
+				v.Commit = s.Value
+			case s.Key == "vcs.time" && v.BuildTime == "":
+				v.BuildTime = s.Value
+			}
+		}
+	}
+	if v.Commit == "" {
+		v.Commit = "unknown"
+	}
+	if v.BuildTime == "" {
+		v.BuildTime = "unknown"
+	}
+
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, v)
+	}
+}
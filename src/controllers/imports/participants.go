@@ -0,0 +1,375 @@
+package imports
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// Columns of the participants file, looked up by their own name in the
+// header unless the mapping says otherwise
+var columns = []string{"firstname", "lastname", "age"}
+
+// Participants imports a CSV or XLSX file of participants, optionally
+// registering each one for the event given in the query. Every row is
+// imported on its own, the invalid ones are reported and skipped. With
+// dry_run=true nothing is committed. Progress is streamed as NDJSON when
+// the client accepts application/x-ndjson.
+func Participants() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			db     = storage.Get(constants.Persistence)
+			report = models.ImportReport{Errors: make([]models.RowError, 0)}
+			event  int
+			err    error
+		)
+
+		if v := c.QueryParam("dry_run"); v != "" {
+			if report.DryRun, err = strconv.ParseBool(v); err != nil {
+				return controllers.Invalid("The dry_run query param is not valid", models.FieldError{
+					Field:   "dry_run",
+					Message: "must be true or false",
+				})
+			}
+		}
+
+		if v := c.QueryParam("event"); v != "" {
+			if event, err = strconv.Atoi(v); err != nil || event < 1 {
+				return controllers.Invalid("The event query param is not valid", models.FieldError{
+					Field:   "event",
+					Message: "must be the ID of an event",
+				})
+			}
+		}
+
+		m, err := mapping(c.QueryParam("mapping"))
+		if err != nil {
+			return err
+		}
+
+		rows, file, err := source(c)
+		if file != nil {
+			defer file.Close()
+		}
+		if err != nil {
+			return err
+		}
+
+		header, err := rows.Read()
+		if err == io.EOF {
+			return controllers.BadRequest("The file is empty")
+		}
+		if err != nil {
+			return controllers.BadRequest("The header of the file could not be read")
+		}
+
+		index, err := resolve(header, m)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var eq, pq, tq string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			eq = "SELECT EXISTS (SELECT 1 FROM events WHERE id = $1);"
+			pq = "INSERT INTO participants(firstname, lastname, age) VALUES($1, $2, $3) RETURNING id;"
+			tq = "INSERT INTO tickets(participant, event) VALUES($1, $2);"
+		case storage.MySQL:
+			eq = "SELECT EXISTS (SELECT 1 FROM events WHERE id = ?);"
+			pq = "INSERT INTO participants(firstname, lastname, age) VALUES(?, ?, ?);"
+			tq = "INSERT INTO tickets(participant, event) VALUES(?, ?);"
+		}
+
+		ctx := c.Request().Context()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return controllers.Internal("The transaction could not be started", err)
+		}
+		defer tx.Rollback()
+
+		if event != 0 {
+			var exists bool
+			if err = tx.QueryRowContext(ctx, eq, event).Scan(&exists); err != nil {
+				return controllers.Internal("The event could not be checked", err)
+			}
+			if !exists {
+				return controllers.NotFound("Event not found")
+			}
+		}
+
+		pStmt, err := tx.PrepareContext(ctx, pq)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = pStmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		tStmt, err := tx.PrepareContext(ctx, tq)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = tStmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var (
+			res    = c.Response()
+			stream = strings.Contains(c.Request().Header.Get(echo.HeaderAccept), constants.MIMEApplicationNDJSON)
+			enc    = json.NewEncoder(res)
+		)
+
+		// Once the stream started the status can't change anymore, errors
+		// are sent as the last message
+		var abort = func(err error) error {
+			if !stream {
+				return err
+			}
+			return enc.Encode(map[string]interface{}{"error": controllers.ProblemOf(c, err)})
+		}
+
+		var fail = func(row int, detail string, fields ...models.FieldError) {
+			report.Failed++
+			report.Errors = append(report.Errors, models.RowError{Row: row, Detail: detail, Errors: fields})
+		}
+
+		if stream {
+			res.Header().Set(echo.HeaderContentType, constants.MIMEApplicationNDJSON)
+			res.WriteHeader(http.StatusOK)
+			res.Flush()
+		}
+
+		for row := 2; ; row++ {
+			record, err := rows.Read()
+			if err == io.EOF {
+				break
+			}
+
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				report.Rows++
+				fail(row, "The row could not be parsed: "+parseErr.Err.Error())
+				continue
+			}
+			if err != nil {
+				return abort(controllers.BadRequest("The file could not be read, it may exceed the size limit"))
+			}
+
+			if blank(record) {
+				continue
+			}
+			report.Rows++
+
+			participant, fields := parseRow(record, index)
+			if len(fields) > 0 {
+				fail(row, "The participant is not valid", fields...)
+			} else if detail, err := insert(ctx, tx, pStmt, tStmt, participant, event); err != nil {
+				return abort(controllers.Internal("The import was interrupted", err))
+			} else if detail != "" {
+				fail(row, detail)
+			} else {
+				report.Imported++
+				if event != 0 {
+					report.Registered++
+				}
+			}
+
+			if stream && report.Rows%constants.ImportProgressRow == 0 {
+				err = enc.Encode(map[string]interface{}{"progress": models.ImportProgress{
+					Rows:     report.Rows,
+					Imported: report.Imported,
+					Failed:   report.Failed,
+				}})
+				if err != nil {
+					return err
+				}
+				res.Flush()
+			}
+		}
+
+		if !report.DryRun {
+			if err = tx.Commit(); err != nil {
+				return abort(controllers.Internal("The transaction could not be committed", err))
+			}
+			metrics.ParticipantsCreated.Add(float64(report.Imported))
+			metrics.TicketsCreated.Add(float64(report.Registered))
+		}
+
+		if stream {
+			return enc.Encode(map[string]interface{}{"report": report})
+		}
+		return controllers.Respond(c, http.StatusOK, report)
+	}
+}
+
+// insert writes a row within its own savepoint, detail is set when the row
+// was rejected and err when the import can't go on
+func insert(ctx context.Context, tx *sql.Tx, pStmt, tStmt *sql.Stmt, p models.Participant, event int) (detail string, err error) {
+	if _, err = tx.ExecContext(ctx, "SAVEPOINT import_row;"); err != nil {
+		return "", err
+	}
+
+	detail = func() string {
+		var id int64
+
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			if err := pStmt.QueryRowContext(ctx, p.Firstname, p.Lastname, p.Age).Scan(&id); err != nil {
+				return "The participant was rejected, not valid"
+			}
+
+		case storage.MySQL:
+			r, err := pStmt.ExecContext(ctx, p.Firstname, p.Lastname, p.Age)
+			if err != nil {
+				return "The participant was rejected, not valid"
+			}
+			if id, err = r.LastInsertId(); err != nil {
+				return "The ID of the new participant could not be retrieved"
+			}
+		}
+
+		if event != 0 {
+			if _, err := tStmt.ExecContext(ctx, id, event); err != nil {
+				return "The participant could not be registered for the event"
+			}
+		}
+		return ""
+	}()
+
+	if detail != "" {
+		_, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row;")
+	} else {
+		_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row;")
+	}
+	return detail, err
+}
+
+// mapping parses the mapping query param, e.g. firstname:Nombre,age:Edad
+func mapping(param string) (map[string]string, error) {
+	var m = make(map[string]string, len(columns))
+	for _, col := range columns {
+		m[col] = col
+	}
+
+	if param == "" {
+		return m, nil
+	}
+
+	for _, pair := range strings.Split(param, ",") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 {
+			kv = append(kv, "")
+		}
+		col, header := strings.ToLower(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+
+		if _, known := m[col]; !known || header == "" {
+			return nil, controllers.Invalid("The mapping query param is not valid", models.FieldError{
+				Field:   "mapping",
+				Message: "must be a list of column:header pairs of " + strings.Join(columns, ", ") + ", e.g. firstname:Nombre,age:Edad",
+			})
+		}
+		m[col] = header
+	}
+	return m, nil
+}
+
+// resolve finds the position of each column in the header, lastname is
+// the only one that can be missing
+func resolve(header []string, m map[string]string) (map[string]int, error) {
+	var (
+		index  = make(map[string]int, len(columns))
+		fields []models.FieldError
+	)
+
+	for _, col := range columns {
+		index[col] = -1
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), m[col]) {
+				index[col] = i
+				break
+			}
+		}
+
+		if index[col] == -1 && col != "lastname" {
+			fields = append(fields, models.FieldError{Field: col, Message: `no "` + m[col] + `" column in the header`})
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, controllers.Invalid("The header of the file doesn't have the mapped columns", fields...)
+	}
+	return index, nil
+}
+
+func parseRow(record []string, index map[string]int) (models.Participant, []models.FieldError) {
+	var cell = func(col string) string {
+		if i := index[col]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var (
+		participant = models.Participant{Firstname: cell("firstname"), Lastname: cell("lastname")}
+		fields      []models.FieldError
+	)
+
+	// Spreadsheets may store whole numbers as 30.0
+	age, err := strconv.ParseFloat(cell("age"), 64)
+	switch {
+	case cell("age") == "":
+		fields = append(fields, models.FieldError{Field: "age", Message: "is required"})
+	case err != nil || age != math.Trunc(age):
+		fields = append(fields, models.FieldError{Field: "age", Message: "must be an integer"})
+	case age >= 0 && age <= math.MaxUint8:
+		participant.Age = uint8(age)
+	default:
+		participant.Age = math.MaxUint8 // Out of range anyway
+	}
+
+	for _, f := range participant.Validate() {
+		if f.Field == "age" && len(fields) > 0 {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return participant, fields
+}
+
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
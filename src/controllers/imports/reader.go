@@ -0,0 +1,120 @@
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// Rows of a spreadsheet, io.EOF after the last one
+type rowReader interface {
+	Read() ([]string, error)
+}
+
+// source opens the uploaded file, either the 'file' field of a multipart
+// form or the request body itself, as CSV or XLSX. The file must be
+// closed once all the rows are read.
+func source(c echo.Context) (rowReader, io.Closer, error) {
+	var (
+		req         = c.Request()
+		body        io.ReadCloser
+		mediaType   string
+		contentType = req.Header.Get(echo.HeaderContentType)
+	)
+
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, constants.MaxImportSize)
+
+	if strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, nil, controllers.Invalid("The file could not be read from the form", models.FieldError{
+				Field:   "file",
+				Message: "is required",
+			})
+		}
+
+		if body, err = fh.Open(); err != nil {
+			return nil, nil, controllers.BadRequest("The file could not be opened")
+		}
+
+		switch strings.ToLower(filepath.Ext(fh.Filename)) {
+		case ".csv":
+			mediaType = constants.MIMETextCSV
+		case ".xlsx":
+			mediaType = constants.MIMEApplicationXLSX
+		default:
+			mediaType, _, _ = mime.ParseMediaType(fh.Header.Get(echo.HeaderContentType))
+		}
+	} else {
+		body = req.Body
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+
+	switch mediaType {
+	case constants.MIMETextCSV:
+		r, err := csvReader(c, body)
+		return r, body, err
+
+	case constants.MIMEApplicationXLSX:
+		data, err := io.ReadAll(body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, body, controllers.TooLarge("The file is over " + strconv.FormatInt(constants.MaxImportSize>>20, 10) + " MiB")
+		}
+		if err != nil {
+			return nil, body, controllers.BadRequest("The file could not be read")
+		}
+		r, err := xlsxReader(data)
+		return r, body, err
+
+	default:
+		return nil, body, controllers.UnsupportedMediaType("The file must be sent as " + constants.MIMETextCSV + " or " + constants.MIMEApplicationXLSX)
+	}
+}
+
+func csvReader(c echo.Context, body io.Reader) (rowReader, error) {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	if d := c.QueryParam("delimiter"); d != "" {
+		comma, size := utf8.DecodeRuneInString(d)
+		if size != len(d) || comma == '"' || comma == '\r' || comma == '\n' {
+			return nil, controllers.Invalid("The delimiter query param is not valid", models.FieldError{
+				Field:   "delimiter",
+				Message: "must be a single character",
+			})
+		}
+		r.Comma = comma
+	}
+
+	return &bomReader{r: r}, nil
+}
+
+// bomReader drops the byte order mark spreadsheet programs put at the
+// beginning of the CSV files they export
+type bomReader struct {
+	r    *csv.Reader
+	read bool
+}
+
+func (b *bomReader) Read() ([]string, error) {
+	record, err := b.r.Read()
+	if !b.read && len(record) > 0 {
+		record[0] = strings.TrimPrefix(record[0], "\ufeff")
+	}
+	b.read = true
+	return record, err
+}
@@ -0,0 +1,239 @@
+package imports
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+)
+
+const (
+	maxRows    = 1048576 // Rows of a worksheet
+	maxColumns = 16384   // Columns of a worksheet, A to XFD
+)
+
+var errTooLarge = errors.New("the part is too large")
+
+// Only what's needed to read the cells of the first worksheet, see
+// ECMA-376 Part 1, section 18 (SpreadsheetML)
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			Id string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+
+	xlsxRelationships struct {
+		Relationships []struct {
+			Id     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+
+	xlsxSharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+
+	// Plain <t> or rich text runs <r><t>
+	xlsxText struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+
+	xlsxWorksheet struct {
+		Rows []struct {
+			Ref   int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// rows of a sheet already in memory, a worksheet is a single XML document
+type sliceReader struct {
+	rows [][]string
+	next int
+}
+
+func (s *sliceReader) Read() ([]string, error) {
+	if s.next >= len(s.rows) {
+		return nil, io.EOF
+	}
+	s.next++
+	return s.rows[s.next-1], nil
+}
+
+func xlsxReader(data []byte) (rowReader, error) {
+	var (
+		invalid  = controllers.BadRequest("The file is not a valid XLSX workbook")
+		tooLarge = controllers.TooLarge("A part of the workbook is over " + strconv.FormatInt(constants.MaxImportXMLSize>>20, 10) + " MiB once uncompressed")
+	)
+
+	// Every part that can't be read makes the workbook invalid, the ones
+	// over the limit make it too large
+	read := func(f *zip.File, v interface{}) error {
+		err := decodeXML(f, v)
+		switch {
+		case errors.Is(err, errTooLarge):
+			return tooLarge
+		case err != nil:
+			return invalid
+		}
+		return nil
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, invalid
+	}
+
+	var files = make(map[string]*zip.File, len(z.File))
+	for _, f := range z.File {
+		files[f.Name] = f
+	}
+
+	var (
+		workbook xlsxWorkbook
+		rels     xlsxRelationships
+		shared   xlsxSharedStrings
+		sheet    xlsxWorksheet
+	)
+
+	if err = read(files["xl/workbook.xml"], &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, invalid
+	}
+	if err = read(files["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return nil, err
+	}
+	// Workbooks without text cells don't have shared strings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err = read(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var target string
+	for _, r := range rels.Relationships {
+		if r.Id == workbook.Sheets[0].Id {
+			target = r.Target
+		}
+	}
+	// Targets are relative to xl/ unless they are absolute
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	if err = read(files[target], &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows = make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		if row.Ref > maxRows {
+			return nil, invalid
+		}
+		// Empty rows aren't written, keep the numbering of the sheet
+		for row.Ref > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+
+		var record []string
+
+		for i, cell := range row.Cells {
+			col := column(cell.Ref)
+			if col < 0 {
+				col = i
+			}
+			if col >= maxColumns {
+				return nil, invalid
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(cell.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, invalid
+				}
+				record[col] = shared.Items[n].String()
+			case "inlineStr":
+				record[col] = cell.Inline.String()
+			default:
+				record[col] = cell.Value
+			}
+		}
+		rows = append(rows, record)
+	}
+
+	return &sliceReader{rows: rows}, nil
+}
+
+// decodeXML of a part, errTooLarge past MaxImportXMLSize uncompressed,
+// whatever size its header says
+func decodeXML(f *zip.File, v interface{}) error {
+	if f == nil {
+		return io.ErrUnexpectedEOF
+	}
+	if f.UncompressedSize64 > uint64(constants.MaxImportXMLSize) {
+		return errTooLarge
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var limited = &io.LimitedReader{R: r, N: constants.MaxImportXMLSize + 1}
+	if err = xml.NewDecoder(limited).Decode(v); err != nil && limited.N <= 0 {
+		return errTooLarge
+	}
+	return err
+}
+
+// Zero-based column of a cell reference, e.g. 'C12' is 2, -1 if there
+// is no reference. The ones past XFD are maxColumns.
+func column(ref string) int {
+	var n int
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		if n = n*26 + int(r-'A'+1); n > maxColumns {
+			return maxColumns
+		}
+	}
+	return n - 1
+}
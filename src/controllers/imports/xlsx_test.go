@@ -0,0 +1,173 @@
+package imports
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+)
+
+const (
+	workbookXML = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="A" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	relsXML     = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`
+	stringsXML  = `<sst><si><t>firstname</t></si><si><r><t>Ju</t></r><r><t>an</t></r></si></sst>`
+)
+
+// workbook with a single sheet, its rows are the content of sheetData
+func workbook(t *testing.T, rows string) []byte {
+	t.Helper()
+
+	return zipOf(t, map[string]string{
+		"xl/workbook.xml":            workbookXML,
+		"xl/_rels/workbook.xml.rels": relsXML,
+		"xl/sharedStrings.xml":       stringsXML,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + rows + `</sheetData></worksheet>`,
+	})
+}
+
+func zipOf(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		z   = zip.NewWriter(&buf)
+	)
+	for name, content := range parts {
+		w, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// bomb is a workbook whose sheet is content compressed, with the
+// uncompressed size the header claims
+func bomb(t *testing.T, content []byte, claimed uint64) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		z   = zip.NewWriter(&buf)
+	)
+	for name, content := range map[string]string{
+		"xl/workbook.xml":            workbookXML,
+		"xl/_rels/workbook.xml.rels": relsXML,
+	} {
+		w, _ := z.Create(name)
+		_, _ = io.WriteString(w, content)
+	}
+
+	var compressed bytes.Buffer
+	fw, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	_, _ = fw.Write(content)
+	_ = fw.Close()
+
+	w, err := z.CreateRaw(&zip.FileHeader{
+		Name:               "xl/worksheets/sheet1.xml",
+		Method:             zip.Deflate,
+		CRC32:              crc32.ChecksumIEEE(content),
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: claimed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(compressed.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err = z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXReader(t *testing.T) {
+	r, err := xlsxReader(workbook(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><t>age</t></is></c></row><row r="3"><c r="B3" t="s"><v>1</v></c><c r="XFD3"><v>30</v></c></row>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		rows = append(rows, record)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("Read %d rows, want 3 with the empty one", len(rows))
+	}
+	if got := strings.Join(rows[0], ","); got != "firstname,,age" {
+		t.Errorf("Row 1 = %q, want %q", got, "firstname,,age")
+	}
+	if len(rows[1]) != 0 {
+		t.Errorf("Row 2 = %q, want an empty row", rows[1])
+	}
+	if len(rows[2]) != maxColumns || rows[2][1] != "Juan" || rows[2][maxColumns-1] != "30" {
+		t.Errorf("Row 3 has %d columns, B is %q, want %d columns with Juan in B and 30 in XFD", len(rows[2]), rows[2][1], maxColumns)
+	}
+}
+
+func TestXLSXReaderRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   func(t *testing.T) []byte
+		status int
+	}{
+		{name: "not a zip", data: func(*testing.T) []byte { return []byte("firstname,age") }, status: http.StatusBadRequest},
+		{name: "column past XFD", data: func(t *testing.T) []byte { return workbook(t, `<row r="1"><c r="XFE1"><v>1</v></c></row>`) }, status: http.StatusBadRequest},
+		{name: "huge column", data: func(t *testing.T) []byte { return workbook(t, `<row r="1"><c r="ZZZZZZZ1"><v>1</v></c></row>`) }, status: http.StatusBadRequest},
+		{name: "overflowing column", data: func(t *testing.T) []byte {
+			return workbook(t, `<row r="1"><c r="`+strings.Repeat("Z", 40)+`1"><v>1</v></c></row>`)
+		}, status: http.StatusBadRequest},
+		{name: "row past the last one", data: func(t *testing.T) []byte { return workbook(t, `<row r="1048577"><c r="A1048577"><v>1</v></c></row>`) }, status: http.StatusBadRequest},
+		{name: "unknown shared string", data: func(t *testing.T) []byte { return workbook(t, `<row r="1"><c r="A1" t="s"><v>9</v></c></row>`) }, status: http.StatusBadRequest},
+		{name: "without worksheet", data: func(t *testing.T) []byte {
+			return zipOf(t, map[string]string{"xl/workbook.xml": workbookXML, "xl/_rels/workbook.xml.rels": relsXML})
+		}, status: http.StatusBadRequest},
+		{name: "part over the limit", data: func(t *testing.T) []byte {
+			return bomb(t, []byte(`<worksheet/>`), uint64(constants.MaxImportXMLSize)+1)
+		}, status: http.StatusRequestEntityTooLarge},
+		{name: "part larger than its header", data: func(t *testing.T) []byte {
+			return bomb(t, []byte(`<worksheet><sheetData>`+strings.Repeat(" ", 1<<20)+`</sheetData></worksheet>`), 1024)
+		}, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := xlsxReader(tt.data(t))
+			if err == nil {
+				t.Fatal("The workbook was read, want an error")
+			}
+			if status, _, _ := controllers.StatusOf(err); status != tt.status {
+				t.Errorf("The status is %d, want %d: %v", status, tt.status, err)
+			}
+		})
+	}
+}
+
+func TestColumn(t *testing.T) {
+	tests := map[string]int{"A1": 0, "C12": 2, "Z9": 25, "AA1": 26, "XFD1": maxColumns - 1, "XFE1": maxColumns, "ZZZZZZZZZZZZZZZZ1": maxColumns, "12": -1, "": -1}
+
+	for ref, want := range tests {
+		if got := column(ref); got != want {
+			t.Errorf("column(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// Page parses the limit and offset query params, the total is
+// left to the handler. Streamed collections are exported up to
+// MaxExportLimit items, the ListWriter links the next ones.
+func Page(c echo.Context) (models.Pagination, error) {
+	var (
+		page = models.Pagination{Limit: constants.DefaultPageLimit}
+		max  = constants.MaxPageLimit
+	)
+
+	if listFormat(c) != "" {
+		page.Limit, max = constants.MaxExportLimit, constants.MaxExportLimit
+	}
+
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > max {
+			return page, Invalid("The limit query param is not valid", models.FieldError{
+				Field:   "limit",
+				Message: "must be an integer between 1 and " + strconv.Itoa(max),
+			})
+		}
+		page.Limit = n
+	}
+
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return page, Invalid("The offset query param is not valid", models.FieldError{
+				Field:   "offset",
+				Message: "must be a positive integer",
+			})
+		}
+		page.Offset = n
+	}
+
+	return page, nil
+}
+
+// List responds with 200 even if the collection is empty
+func List(c echo.Context, data interface{}, page models.Pagination) error {
+	var res = envelope(c, data)
+	res.Pagination = &page
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,97 @@
+package participants
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// Agenda exports as iCalendar the scheduled events the participant has a
+// ticket for, the events keep the UIDs of the events feed
+func Agenda() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var db = storage.Get(constants.Persistence)
+
+		id, err := controllers.IntParam(c, "id")
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		ctx := c.Request().Context()
+
+		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT EXISTS (SELECT 1 FROM participants WHERE id = $1);"
+		case storage.MySQL:
+			q = "SELECT EXISTS (SELECT 1 FROM participants WHERE id = ?);"
+		}
+
+		exists, err := controllers.Exists(ctx, db, q, id)
+		if err != nil {
+			return controllers.Internal("The participant could not be checked", err)
+		}
+		if !exists {
+			return controllers.NotFound("Participant not found")
+		}
+
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT e.id, e.name, e.created_at, e.starts_at, e.ends_at, e.version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event WHERE t.participant = $1 AND e.starts_at IS NOT NULL ORDER BY e.starts_at ASC;"
+		case storage.MySQL:
+			q = "SELECT e.id, e.name, e.created_at, e.starts_at, e.ends_at, e.version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event WHERE t.participant = ? AND e.starts_at IS NOT NULL ORDER BY e.starts_at ASC;"
+		}
+
+		stmt, err := db.PrepareContext(ctx, q)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = stmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		rows, err := stmt.QueryContext(ctx, id)
+		if err != nil {
+			return controllers.Internal("There was an error when tried to bring the payload", err)
+		}
+		defer func() {
+			if err = rows.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var cal = controllers.NewCalendar(c, "participant-"+strconv.Itoa(id)+"-agenda")
+		for rows.Next() {
+			var e models.Event
+
+			if err = rows.Scan(&e.Id, &e.Name, &e.Created_at, &e.Starts_at, &e.Ends_at, &e.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = cal.Add(e); err != nil {
+				return err
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
+		}
+
+		return cal.Close()
+	}
+}
@@ -0,0 +1,273 @@
+package participants
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// NewBatch creates an array of participants within a single transaction
+func NewBatch() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			db      = storage.Get(constants.Persistence)
+			request []models.Participant
+		)
+
+		atomic, err := controllers.Atomic(c)
+		if err != nil {
+			return err
+		}
+
+		n, err := controllers.DecodeBatch(c, &request)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "INSERT INTO participants(firstname, lastname, age) VALUES($1, $2, $3) RETURNING id, version;"
+		case storage.MySQL:
+			q = "INSERT INTO participants(firstname, lastname, age) VALUES(?, ?, ?);"
+		}
+
+		ctx := c.Request().Context()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return controllers.Internal("The transaction could not be started", err)
+		}
+		defer tx.Rollback()
+
+		stmt, err := tx.PrepareContext(ctx, q)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = stmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		// Counted once committed
+		var created int
+		err = controllers.Batch(ctx, c, tx, atomic, n, http.StatusCreated, func(ctx context.Context, i int) (int, interface{}, error) {
+			var participant = request[i]
+
+			if fields := participant.Validate(); len(fields) > 0 {
+				return 0, nil, controllers.Invalid("The participant is not valid", fields...)
+			}
+
+			switch constants.Persistence {
+			case storage.PostgreSQL:
+				err := stmt.QueryRowContext(ctx, participant.Firstname, participant.Lastname, participant.Age).Scan(&participant.Id, &participant.Version)
+				if err != nil {
+					return 0, nil, controllers.Rejected(ctx, err, controllers.BadRequest("The participant was rejected, not valid"))
+				}
+
+			case storage.MySQL:
+				r, err := stmt.ExecContext(ctx, participant.Firstname, participant.Lastname, participant.Age)
+				if err != nil {
+					return 0, nil, controllers.Rejected(ctx, err, controllers.BadRequest("The participant was rejected, not valid"))
+				}
+
+				id, err := r.LastInsertId()
+				if err != nil {
+					return 0, nil, controllers.Internal("The ID of the new participant could not be retrieved", err)
+				}
+				participant.Id, participant.Version = uint64(id), 1 // Default version
+			}
+
+			created++
+			return http.StatusCreated, participant, nil
+		})
+		if err == nil {
+			metrics.ParticipantsCreated.Add(float64(created))
+		}
+		return err
+	}
+}
+
+// UpdateBatch replaces an array of participants, identified by their id.
+// The version of an item, if given, works as its If-Match header.
+func UpdateBatch() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			db      = storage.Get(constants.Persistence)
+			request []models.Participant
+		)
+
+		atomic, err := controllers.Atomic(c)
+		if err != nil {
+			return err
+		}
+
+		n, err := controllers.DecodeBatch(c, &request)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var sq, uq string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			sq = "SELECT version FROM participants WHERE id = $1 FOR UPDATE;"
+			uq = "UPDATE participants SET firstname = $1, lastname = $2, age = $3, version = version + 1 WHERE id = $4;"
+		case storage.MySQL:
+			sq = "SELECT version FROM participants WHERE id = ? FOR UPDATE;"
+			uq = "UPDATE participants SET firstname = ?, lastname = ?, age = ?, version = version + 1 WHERE id = ?;"
+		}
+
+		ctx := c.Request().Context()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return controllers.Internal("The transaction could not be started", err)
+		}
+		defer tx.Rollback()
+
+		sStmt, err := tx.PrepareContext(ctx, sq)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = sStmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		uStmt, err := tx.PrepareContext(ctx, uq)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = uStmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		return controllers.Batch(ctx, c, tx, atomic, n, http.StatusOK, func(ctx context.Context, i int) (int, interface{}, error) {
+			var participant = request[i]
+
+			if participant.Id == 0 {
+				return 0, nil, controllers.Invalid("The participant is not valid", models.FieldError{Field: "id", Message: "is required"})
+			}
+
+			if fields := participant.Validate(); len(fields) > 0 {
+				return 0, nil, controllers.Invalid("The participant is not valid", fields...)
+			}
+
+			var version uint32
+			err := sStmt.QueryRowContext(ctx, participant.Id).Scan(&version)
+			if err == sql.ErrNoRows {
+				return 0, nil, controllers.NotFound("Participant not found")
+			}
+			if err != nil {
+				return 0, nil, controllers.Internal("The participant version could not be retrieved", err)
+			}
+
+			if participant.Version != 0 && participant.Version != version {
+				return 0, nil, controllers.PreconditionFailed("The participant was modified, its current version is " + controllers.ETag(version))
+			}
+
+			if _, err = uStmt.ExecContext(ctx, participant.Firstname, participant.Lastname, participant.Age, participant.Id); err != nil {
+				return 0, nil, controllers.Rejected(ctx, err, controllers.BadRequest("The participant was rejected, not valid"))
+			}
+
+			participant.Version = version + 1
+			return http.StatusOK, participant, nil
+		})
+	}
+}
+
+// RemoveBatch deletes an array of participants given their IDs
+func RemoveBatch() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			db      = storage.Get(constants.Persistence)
+			request []uint64
+		)
+
+		atomic, err := controllers.Atomic(c)
+		if err != nil {
+			return err
+		}
+
+		n, err := controllers.DecodeBatch(c, &request)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "DELETE FROM participants WHERE id = $1;"
+		case storage.MySQL:
+			q = "DELETE FROM participants WHERE id = ?;"
+		}
+
+		ctx := c.Request().Context()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return controllers.Internal("The transaction could not be started", err)
+		}
+		defer tx.Rollback()
+
+		stmt, err := tx.PrepareContext(ctx, q)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = stmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		return controllers.Batch(ctx, c, tx, atomic, n, http.StatusOK, func(ctx context.Context, i int) (int, interface{}, error) {
+			r, err := stmt.ExecContext(ctx, request[i])
+			if err != nil {
+				return 0, nil, controllers.Internal("The participant could not be deleted", err)
+			}
+			if n, _ := r.RowsAffected(); n == 0 {
+				return 0, nil, controllers.NotFound("Participant not found")
+			}
+			return http.StatusOK, nil, nil
+		})
+	}
+}
@@ -1,15 +1,14 @@
 package participants
 
 import (
-	"context"
+	"database/sql"
 	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/luisnquin/restapi-technical-test/src/constants"
-	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
@@ -20,47 +19,13 @@ func RemoveById() echo.HandlerFunc {
 			err error
 		)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID parameter cannot be processed as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -69,91 +34,23 @@ func RemoveById() echo.HandlerFunc {
 			}
 		}()
 
-		var q string
-		switch constants.Persistence {
-		case storage.PostgreSQL:
-			q = "DELETE FROM participants WHERE id = $1;"
-		case storage.MySQL:
-			q = "DELETE FROM participants WHERE id = ?;"
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
 
-		stmt, err := db.PrepareContext(ctx, q)
+		version, err := controllers.Version(ctx, db, "participants", id)
+		if err == sql.ErrNoRows {
+			return controllers.NotFound("Participant not found")
+		}
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The participant version could not be retrieved", err)
 		}
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
 
-		r, err := stmt.ExecContext(ctx, id)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The ID parameter was refected, not valid",
-						},
-					},
-				},
-			})
+		if err = controllers.CheckIfMatch(c, version); err != nil {
+			return err
 		}
-		if i, _ := r.RowsAffected(); i == 0 {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.delete",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "Participant not found",
-						},
-					},
-				},
-			})
+
+		if err = repository.DeleteParticipant(ctx, db, int64(id), &version); err != nil {
+			return err
 		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "participants.delete",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-		})
+		return controllers.Respond(c, http.StatusOK, nil)
 	}
 }
@@ -1,15 +1,14 @@
 package participants
 
 import (
-	"context"
-	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
 	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
@@ -22,22 +21,13 @@ func Fetch() echo.HandlerFunc {
 
 		desc, _ := strconv.ParseBool(c.QueryParam("desc"))
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		page, err := controllers.Page(c)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 		defer func() {
 			if err = db.Close(); err != nil {
@@ -45,32 +35,28 @@ func Fetch() echo.HandlerFunc {
 			}
 		}()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
+
+		page.Total, err = controllers.Count(ctx, db, "SELECT COUNT(*) FROM participants;")
+		if err != nil {
+			return controllers.Internal("The participants could not be counted", err)
+		}
 
 		var q string
-		if desc {
-			q = "SELECT * FROM participants ORDER BY id DESC;"
-		} else {
-			q = "SELECT * FROM participants;"
+		switch {
+		case constants.Persistence == storage.PostgreSQL && desc:
+			q = "SELECT id, firstname, lastname, age, version FROM participants ORDER BY id DESC LIMIT $1 OFFSET $2;"
+		case constants.Persistence == storage.MySQL && desc:
+			q = "SELECT id, firstname, lastname, age, version FROM participants ORDER BY id DESC LIMIT ? OFFSET ?;"
+		case constants.Persistence == storage.PostgreSQL:
+			q = "SELECT id, firstname, lastname, age, version FROM participants ORDER BY id ASC LIMIT $1 OFFSET $2;"
+		case constants.Persistence == storage.MySQL:
+			q = "SELECT id, firstname, lastname, age, version FROM participants ORDER BY id ASC LIMIT ? OFFSET ?;"
 		}
 
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
 			if err = stmt.Close(); err != nil {
@@ -78,110 +64,45 @@ func Fetch() echo.HandlerFunc {
 			}
 		}()
 
-		rows, err := stmt.QueryContext(ctx)
+		rows, err := stmt.QueryContext(ctx, page.Limit, page.Offset)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "There was an error when tried to bring the payload",
-						},
-					},
-				},
-			})
+			return controllers.Internal("There was an error when tried to bring the payload", err)
 		}
+		defer func() {
+			if err = rows.Close(); err != nil {
+				panic(err)
+			}
+		}()
 
-		var participants models.Participants
+		var list = controllers.NewList(c, page, "participants", models.Participant{})
 		for rows.Next() {
 			var p models.Participant
-			if err = rows.Scan(&p.Id, &p.Firstname, &p.Lastname, &p.Age); err != nil {
-				return c.JSON(http.StatusConflict, models.BadResponse{
-					APIVersion: constants.APIVersion,
-					Method:     "participants.get",
-					Context:    c.Request().URL.String(),
-					Error: models.Error{
-						Code:    409,
-						Message: "Conflict",
-						Errors: []map[string]interface{}{
-							{
-								"reason":  "Conflict",
-								"message": "An error was logged while trying to process the payload",
-							},
-						},
-					},
-				})
+			if err = rows.Scan(&p.Id, &p.Firstname, &p.Lastname, &p.Age, &p.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = list.Add(p); err != nil {
+				return err
 			}
-			participants = append(participants, p)
 		}
-
-		if len(participants) == 0 {
-			return c.JSON(http.StatusNoContent, models.SuccessfulResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-			})
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
 		}
 
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "participants.get",
-			Context:    c.Request().URL.String(),
-			Data:       participants,
-		})
+		return list.Close()
 	}
 }
 
 func FetchById() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		var db  = storage.Get(constants.Persistence)
+		var db = storage.Get(constants.Persistence)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID parameter provided cannot be processed as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -190,128 +111,34 @@ func FetchById() echo.HandlerFunc {
 			}
 		}()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
 
-		var q string
-		switch constants.Persistence {
-		case storage.PostgreSQL:
-			q = "SELECT * FROM participants WHERE id = $1 LIMIT 1;"
-		case storage.MySQL:
-			q = "SELECT * FROM participants WHERE id = ? LIMIT 1;"
-		}
-
-		stmt, err := db.PrepareContext(ctx, q)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
-		}
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
-
-		var p models.Participant
-		err = stmt.QueryRowContext(ctx, id).Scan(&p.Id, &p.Firstname, &p.Lastname, &p.Age)
+		p, err := repository.GetParticipant(ctx, db, int64(id))
 		if err != nil {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "Participant not found",
-						},
-					},
-				},
-			})
+			return err
 		}
-
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "participants.get",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-			Data: p,
-		})
+		return controllers.Entity(c, p.Version, p)
 	}
 }
 
 func FetchTicketsById() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		var (
-			db  = storage.Get(constants.Persistence)
-			err error
-		)
+		var db = storage.Get(constants.Persistence)
 
 		desc, _ := strconv.ParseBool(c.QueryParam("desc"))
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID provided cannot be processed as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		page, err := controllers.Page(c)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -320,40 +147,50 @@ func FetchTicketsById() echo.HandlerFunc {
 			}
 		}()
 
+		ctx := c.Request().Context()
+
 		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT EXISTS (SELECT 1 FROM participants WHERE id = $1);"
+		case storage.MySQL:
+			q = "SELECT EXISTS (SELECT 1 FROM participants WHERE id = ?);"
+		}
+
+		exists, err := controllers.Exists(ctx, db, q, id)
+		if err != nil {
+			return controllers.Internal("The participant could not be checked", err)
+		}
+		if !exists {
+			return controllers.NotFound("Participant not found")
+		}
+
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "SELECT COUNT(*) FROM tickets WHERE participant = $1;"
+		case storage.MySQL:
+			q = "SELECT COUNT(*) FROM tickets WHERE participant = ?;"
+		}
+
+		page.Total, err = controllers.Count(ctx, db, q, id)
+		if err != nil {
+			return controllers.Internal("The tickets could not be counted", err)
+		}
+
 		switch {
 		case constants.Persistence == storage.PostgreSQL && desc:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = $1 ORDER BY t.id DESC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = $1 ORDER BY t.id DESC LIMIT $2 OFFSET $3;"
 		case constants.Persistence == storage.MySQL && desc:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = ? ORDER BY t.id DESC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = ? ORDER BY t.id DESC LIMIT ? OFFSET ?;"
 		case constants.Persistence == storage.PostgreSQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = $1 ORDER BY t.id ASC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = $1 ORDER BY t.id ASC LIMIT $2 OFFSET $3;"
 		case constants.Persistence == storage.MySQL:
-			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = ? ORDER BY t.id ASC;"
+			q = "SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant WHERE p.id = ? ORDER BY t.id ASC LIMIT ? OFFSET ?;"
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
 			if err = stmt.Close(); err != nil {
@@ -361,74 +198,32 @@ func FetchTicketsById() echo.HandlerFunc {
 			}
 		}()
 
-		rows, err := stmt.QueryContext(ctx, id)
+		rows, err := stmt.QueryContext(ctx, id, page.Limit, page.Offset)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "The ID parameter was rejected, not valid",
-						},
-					},
-				},
-			})
+			return controllers.Internal("There was an error when tried to bring the payload", err)
 		}
+		defer func() {
+			if err = rows.Close(); err != nil {
+				panic(err)
+			}
+		}()
 
-		var tviews models.TicketViews
-
+		var list = controllers.NewList(c, page, "tickets", models.TicketView{})
 		for rows.Next() {
 			var tview models.TicketView
 
-			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event); err != nil {
-				return c.JSON(http.StatusConflict, models.BadResponse{
-					APIVersion: constants.APIVersion,
-					Method:     "participants.get",
-					Context:    c.Request().URL.String(),
-					Params: map[string]interface{}{
-						"id": id,
-					},
-					Error: models.Error{
-						Code:    409,
-						Message: "Conflict",
-						Errors: []map[string]interface{}{
-							{
-								"reason":  "Conflict",
-								"message": "An error was logged while trying to process the payload",
-							},
-						},
-					},
-				})
+			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event, &tview.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = list.Add(tview); err != nil {
+				return err
 			}
-			tviews = append(tviews, tview)
 		}
-		if len(tviews) == 0 {
-			return c.JSON(http.StatusNoContent, models.SuccessfulResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-			})
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
 		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "participants.get",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-			Data: tviews,
-		})
+
+		return list.Close()
 	}
 }
 
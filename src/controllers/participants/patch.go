@@ -0,0 +1,55 @@
+package participants
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// ModifyById applies a JSON Merge Patch or a JSON Patch to a participant,
+// unlike UpdateById a member can be cleared by setting it to null.
+func ModifyById() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var db = storage.Get(constants.Persistence)
+
+		id, err := controllers.IntParam(c, "id")
+		if err != nil {
+			return err
+		}
+
+		if err = controllers.CheckPatchType(c); err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		ctx := c.Request().Context()
+
+		participant, err := repository.PatchParticipant(ctx, db, int64(id), func(current models.Participant) (models.Participant, error) {
+			var patched models.Participant
+			if err := controllers.CheckIfMatch(c, current.Version); err != nil {
+				return patched, err
+			}
+			return patched, controllers.ApplyPatch(c, current, &patched, "id", "version")
+		})
+		if err != nil {
+			return err
+		}
+
+		controllers.SetETag(c, participant.Version)
+		return controllers.Respond(c, http.StatusOK, participant)
+	}
+}
@@ -1,15 +1,15 @@
 package participants
 
 import (
-	"context"
+	"database/sql"
 	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
 	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
@@ -20,89 +20,21 @@ func UpdateById() echo.HandlerFunc {
 			request = new(models.Participant)
 		)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID parameter cannot be processed as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
 
 		if err = c.Bind(request); err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad request",
-							"message": "The request body data is not valid",
-						},
-					},
-				},
-			})
+			return controllers.BadRequest("The request body data is not valid")
 		}
 
 		if (*request == models.Participant{}) {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "The request body data is empty",
-							"message": "Bad Request",
-						},
-					},
-				},
-			})
+			return controllers.BadRequest("The request body data is empty")
 		}
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 		defer func() {
 			if err = db.Close(); err != nil {
@@ -110,92 +42,26 @@ func UpdateById() echo.HandlerFunc {
 			}
 		}()
 
-		var q string
-		switch constants.Persistence {
-		case storage.PostgreSQL:
-			q = "UPDATE participants SET firstname = $1, lastname = $2, age = $3 WHERE id = $4;"
-		case storage.MySQL:
-			q = "UPDATE participants SET firstname = ?, lastname = ?, age = ? WHERE id = ?;"
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
 
-		stmt, err := db.PrepareContext(ctx, q)
+		version, err := controllers.Version(ctx, db, "participants", id)
+		if err == sql.ErrNoRows {
+			return controllers.NotFound("Participant not found")
+		}
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The participant version could not be retrieved", err)
 		}
 
-		defer func() {
-			if err = stmt.Close(); err != nil {
-				panic(err)
-			}
-		}()
+		if err = controllers.CheckIfMatch(c, version); err != nil {
+			return err
+		}
 
-		r, err := stmt.ExecContext(ctx, request.Firstname, request.Lastname, request.Age, id)
+		participant, err := repository.UpdateParticipant(ctx, db, int64(id), *request, &version)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The request body or param was rejected, not valid",
-						},
-					},
-				},
-			})
+			return err
 		}
-		if i, _ := r.RowsAffected(); i == 0 {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "participants.put",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "Participant not found",
-						},
-					},
-				},
-			})
-		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "participants.put",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-		})
+
+		controllers.SetETag(c, participant.Version)
+		return controllers.Respond(c, http.StatusOK, nil)
 	}
 }
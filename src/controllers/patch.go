@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+const headerAcceptPatch = "Accept-Patch"
+
+// ApplyPatch applies the request body, a JSON Merge Patch (RFC 7396) or a
+// JSON Patch (RFC 6902) depending on the Content-Type, to the current
+// representation of a resource and decodes the result into patched.
+// The read-only members cannot be modified by the patch.
+func ApplyPatch(c echo.Context, current, patched interface{}, readOnly ...string) error {
+	mediaType, err := patchType(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return BadRequest("The request body could not be read")
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return Internal("The resource could not be encoded", err)
+	}
+
+	var doc []byte
+	switch mediaType {
+	case constants.MIMEApplicationMergePatchJSON:
+		if doc, err = jsonpatch.MergePatch(original, body); err != nil {
+			return BadRequest("The request body is not a valid JSON Merge Patch document")
+		}
+
+	case constants.MIMEApplicationJSONPatchJSON:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return BadRequest("The request body is not a valid JSON Patch document")
+		}
+		if doc, err = patch.Apply(original); err != nil {
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return Conflict("A test operation of the patch failed")
+			}
+			return Invalid("The patch cannot be applied to the resource: " + err.Error())
+		}
+	}
+
+	if fields := readOnlyChanges(original, doc, readOnly); len(fields) > 0 {
+		return Invalid("The patch modifies read-only members", fields...)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(patched); err != nil {
+		return Invalid("The patched resource is not valid", decodeError(err))
+	}
+	return nil
+}
+
+// CheckPatchType rejects a patch in an unsupported format before any
+// work is done with the resource
+func CheckPatchType(c echo.Context) error {
+	_, err := patchType(c)
+	return err
+}
+
+func patchType(c echo.Context) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+
+	if mediaType != constants.MIMEApplicationMergePatchJSON && mediaType != constants.MIMEApplicationJSONPatchJSON {
+		c.Response().Header().Set(headerAcceptPatch, constants.MIMEApplicationMergePatchJSON+", "+constants.MIMEApplicationJSONPatchJSON)
+		return "", UnsupportedMediaType("The patch must be sent as " + constants.MIMEApplicationMergePatchJSON + " or " + constants.MIMEApplicationJSONPatchJSON)
+	}
+	return mediaType, nil
+}
+
+func readOnlyChanges(original, patched []byte, members []string) []models.FieldError {
+	var before, after map[string]json.RawMessage
+
+	if json.Unmarshal(original, &before) != nil || json.Unmarshal(patched, &after) != nil {
+		return []models.FieldError{{Field: "", Message: "must be an object"}}
+	}
+
+	var fields []models.FieldError
+	for _, member := range members {
+		if !bytes.Equal(before[member], after[member]) {
+			fields = append(fields, models.FieldError{Field: member, Message: "is read-only"})
+		}
+	}
+	return fields
+}
+
+func decodeError(err error) models.FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return models.FieldError{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()}
+	}
+
+	// There is no typed error for unknown fields: json: unknown field "name"
+	if msg := err.Error(); strings.HasPrefix(msg, "json: unknown field ") {
+		return models.FieldError{
+			Field:   strings.Trim(strings.TrimPrefix(msg, "json: unknown field "), `"`),
+			Message: "is not a member of the resource",
+		}
+	}
+	return models.FieldError{Field: "", Message: err.Error()}
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+func TestApplyPatch(t *testing.T) {
+	var (
+		merge   = constants.MIMEApplicationMergePatchJSON
+		patch   = constants.MIMEApplicationJSONPatchJSON
+		current = models.Participant{Id: 7, Firstname: "Ana", Lastname: "Diaz", Age: 30, Version: 3}
+	)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        models.Participant
+		status      int      // 0 if the patch applies
+		fields      []string // of the 422
+	}{
+		{
+			name:        "merge patch",
+			contentType: merge,
+			body:        `{"firstname": "Eva", "age": 31}`,
+			want:        models.Participant{Id: 7, Firstname: "Eva", Lastname: "Diaz", Age: 31, Version: 3},
+		},
+		{
+			name:        "merge patch with charset",
+			contentType: merge + "; charset=utf-8",
+			body:        `{"lastname": "Paz"}`,
+			want:        models.Participant{Id: 7, Firstname: "Ana", Lastname: "Paz", Age: 30, Version: 3},
+		},
+		{
+			// null removes the member, decoded as its zero value
+			name:        "merge patch clears a member",
+			contentType: merge,
+			body:        `{"lastname": null}`,
+			want:        models.Participant{Id: 7, Firstname: "Ana", Age: 30, Version: 3},
+		},
+		{
+			name:        "merge patch with the same read-only values",
+			contentType: merge,
+			body:        `{"id": 7, "version": 3, "age": 40}`,
+			want:        models.Participant{Id: 7, Firstname: "Ana", Lastname: "Diaz", Age: 40, Version: 3},
+		},
+		{
+			name:        "JSON Patch",
+			contentType: patch,
+			body:        `[{"op": "test", "path": "/firstname", "value": "Ana"}, {"op": "replace", "path": "/firstname", "value": "Eva"}, {"op": "remove", "path": "/lastname"}]`,
+			want:        models.Participant{Id: 7, Firstname: "Eva", Age: 30, Version: 3},
+		},
+		{
+			// A merge patch of an array replaces the whole resource
+			name:        "JSON Patch sent as merge patch",
+			contentType: merge,
+			body:        `[{"op": "replace", "path": "/firstname", "value": "Eva"}]`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{""},
+		},
+		{
+			name:        "merge patch sent as JSON Patch",
+			contentType: patch,
+			body:        `{"firstname": "Eva"}`,
+			status:      http.StatusBadRequest,
+		},
+		{name: "application/json", contentType: echo.MIMEApplicationJSON, body: `{"firstname": "Eva"}`, status: http.StatusUnsupportedMediaType},
+		{name: "no content type", body: `{"firstname": "Eva"}`, status: http.StatusUnsupportedMediaType},
+		{name: "malformed merge patch", contentType: merge, body: `{"firstname": `, status: http.StatusBadRequest},
+		{
+			name:        "merge patch of the id",
+			contentType: merge,
+			body:        `{"id": 8}`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{"id"},
+		},
+		{
+			name:        "merge patch removes the version",
+			contentType: merge,
+			body:        `{"version": null}`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{"version"},
+		},
+		{
+			name:        "JSON Patch of the id and version",
+			contentType: patch,
+			body:        `[{"op": "replace", "path": "/id", "value": 8}, {"op": "replace", "path": "/version", "value": 4}]`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{"id", "version"},
+		},
+		{
+			name:        "merge patch of an unknown member",
+			contentType: merge,
+			body:        `{"nickname": "Ani"}`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{"nickname"},
+		},
+		{
+			name:        "JSON Patch adds an unknown member",
+			contentType: patch,
+			body:        `[{"op": "add", "path": "/nickname", "value": "Ani"}]`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{"nickname"},
+		},
+		{
+			name:        "wrong type",
+			contentType: merge,
+			body:        `{"age": "thirty"}`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{"age"},
+		},
+		{
+			name:        "out of range",
+			contentType: merge,
+			body:        `{"age": 300}`,
+			status:      http.StatusUnprocessableEntity,
+			fields:      []string{"age"},
+		},
+		{
+			name:        "failed test",
+			contentType: patch,
+			body:        `[{"op": "test", "path": "/firstname", "value": "Eva"}, {"op": "replace", "path": "/firstname", "value": "Lia"}]`,
+			status:      http.StatusConflict,
+		},
+		{
+			name:        "test without path",
+			contentType: patch,
+			body:        `[{"op": "test", "value": "Ana"}]`,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "test of a missing member",
+			contentType: patch,
+			body:        `[{"op": "test", "path": "/nickname", "value": "Ani"}]`,
+			status:      http.StatusConflict,
+		},
+		{
+			name:        "unknown op",
+			contentType: patch,
+			body:        `[{"op": "rename", "path": "/firstname", "value": "Eva"}]`,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "remove of a missing member",
+			contentType: patch,
+			body:        `[{"op": "remove", "path": "/nickname"}]`,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{name: "malformed JSON Patch", contentType: patch, body: `[{"op": `, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/participant/7", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			var patched models.Participant
+			err := ApplyPatch(c, current, &patched, "id", "version")
+
+			if got := statusOf(err); got != tt.status {
+				t.Fatalf("ApplyPatch() = %v, want the status %d", err, tt.status)
+			}
+			if tt.status == 0 && patched != tt.want {
+				t.Errorf("patched = %+v, want %+v", patched, tt.want)
+			}
+			if tt.status == http.StatusUnsupportedMediaType && rec.Header().Get(headerAcceptPatch) == "" {
+				t.Error("The 415 has no Accept-Patch header")
+			}
+			if tt.fields != nil {
+				var invalid *ValidationError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("ApplyPatch() = %v, want a ValidationError", err)
+				}
+				var fields []string
+				for _, f := range invalid.Fields {
+					fields = append(fields, f.Field)
+				}
+				if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+					t.Errorf("fields = %q, want %q", fields, tt.fields)
+				}
+			}
+		})
+	}
+}
@@ -8,6 +8,6 @@ import (
 
 func Help() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		return c.String(http.StatusOK, "Welcome!\n\nThere are an endpoint to build the database in MySQL or PostgreSQL, just fill the request body data with your session credentials later, in the URL, set the database name of your preference and send the request\n\nFile I mean:\n\t -> [ROOT_DIR]/build.rest\n \n\nAnother option: \ncurl -X POST http://127.0.0.1:8000/persistence/build/<database-name> \\\n\t-H 'Content-Type: application/json' \\\n\t-d '{\"dbname\":\"\", \"user\":\"\", \"password\": \"\"}'  \n\nI made it fast so it may fail, in which case you will have to opt for a manual configuration, your tools are in:\n -> [ROOT_DIR]/src/database/<persistence-name>.sql, just press [Ctrl+A] and it will be ready for pasting\n -> [ROOT_DIR]/.env.example to create one customised DSN for your session")
+		return c.String(http.StatusOK, "Welcome!\n\nThere are an endpoint to build the database in MySQL or PostgreSQL, just fill the request body data with your session credentials later, in the URL, set the database name of your preference and send the request\n\nEvery table of the database is dropped, so its name must be repeated in the confirm query parameter, the request must come from the host of the server (or to ADMIN_ADDRESS if the admin listener is set) and the token must have the admin role. In production (APP_ENV=production) the endpoint is off unless PERSISTENCE_BUILD=true\n\nThe server must be started with JWT_SECRET (or JWT_PUBLIC_KEY), the API keys are stored in the database this endpoint creates\n\nFile I mean:\n\t -> [ROOT_DIR]/build.rest\n \n\nAnother option: \ncurl -X POST 'http://127.0.0.1:8000/persistence/build/<database-name>?confirm=<dbname>' \\\n\t-H 'Content-Type: application/json' \\\n\t-H \"Authorization: Bearer $(JWT_SECRET=<secret> authctl token -sub <you> -role admin)\" \\\n\t-d '{\"dbname\":\"\", \"user\":\"\", \"password\": \"\"}'  \n\nI made it fast so it may fail, in which case you will have to opt for a manual configuration, your tools are in:\n -> [ROOT_DIR]/src/database/<persistence-name>.sql, just press [Ctrl+A] and it will be ready for pasting\n -> [ROOT_DIR]/.env.example to create one customised DSN for your session")
 	}
 }
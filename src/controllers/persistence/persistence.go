@@ -4,14 +4,16 @@ import (
 	"database/sql"
 	"net/http"
 	"os"
-	"log"
-	"regexp"
+	"log/slog"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/labstack/echo/v4"
 	_ "github.com/lib/pq"
 
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
 	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
 func Build() echo.HandlerFunc {
@@ -4231,35 +4233,41 @@ func Build() echo.HandlerFunc {
 			err error
 		)
 
-		psql, _ := regexp.Compile(`([pP][oO][Ss][tT][gG][rR][eE][sS]?[qQ]?[Ll]?)|([pP][sS][Qq][lL])`)
-		mysql, _ := regexp.Compile(`[mM][yY][sS][Qq][lL]`)
-
+		kind, ok := persistenceOf(persistence)
+		if !ok {
+			return controllers.BadRequest("The provided persistence doesn't match with none of the available, postgres or mysql")
+		}
 
 		if err = c.Bind(dsn); err != nil {
-			return c.String(http.StatusUnprocessableEntity, "Your request body!")
+			return controllers.BadRequest("The request body data is not valid")
 		}
 		if (*dsn == models.DSN{}) {
-			return c.String(http.StatusBadRequest, "No DSN provided in request body.\n\nSee: http://127.0.0.1:8000/persistence/help")
+			return controllers.BadRequest("No DSN provided in request body, see /persistence/help")
+		}
+
+		// Every table is dropped, the name of the database must be repeated
+		if c.QueryParam("confirm") != dsn.Dbname {
+			return controllers.BadRequest("Every table of the database will be dropped, repeat its name in the confirm query parameter")
 		}
 
-		switch {
-		case psql.Match([]byte(persistence)):
+		switch kind {
+		case storage.PostgreSQL:
 			err = os.Setenv("dsn", "dbname="+dsn.Dbname+" user="+dsn.User+" password="+dsn.Password+" host=localhost port=5432 sslmode=disable")
 			if err != nil {
-				return c.String(http.StatusInternalServerError, "Error setting DSN as environment variable")
+				return controllers.Internal("Error setting DSN as environment variable", err)
 			}
 
 			if err = os.Setenv("PERSISTENCE_NAME", "PostgreSQL"); err != nil {
-				c.String(http.StatusInternalServerError, "Error trying to set the PERSISTENCE_NAME as environment variable")
+				return controllers.Internal("Error trying to set the PERSISTENCE_NAME as environment variable", err)
 			}
 
 			db, err := sql.Open("postgres", os.Getenv("dsn"))
 			if err != nil {
-				return c.String(http.StatusInternalServerError, "The DSN is failing or the database connection is dead")
+				return controllers.Internal("The DSN is failing or the database connection is dead", err)
 			}
 			
 			if err = db.Ping(); err != nil {
-				return c.String(http.StatusInternalServerError, "No response from the database")
+				return controllers.Internal("No response from the database", err)
 			}
 			defer func() {
 				if err = db.Close(); err != nil {
@@ -4270,54 +4278,57 @@ func Build() echo.HandlerFunc {
 			stmts := []string{
 				"DROP SCHEMA IF EXISTS public CASCADE;",
 				"CREATE SCHEMA IF NOT EXISTS public;",
-				"CREATE TABLE IF NOT EXISTS events (id INTEGER GENERATED ALWAYS AS IDENTITY, name VARCHAR(50) NOT NULL, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY(id));",
-				"CREATE TABLE IF NOT EXISTS participants(id INTEGER GENERATED ALWAYS AS IDENTITY, firstname VARCHAR(40) NOT NULL, lastname VARCHAR(40), age NUMERIC(3,0) NOT NULL, CONSTRAINT is_older CHECK(age >= 18), CONSTRAINT is_human CHECK(age < 130), PRIMARY KEY(id));",
-				"CREATE TABLE IF NOT EXISTS tickets(id INTEGER GENERATED ALWAYS AS IDENTITY, event INTEGER NOT NULL, participant INTEGER NOT NULL, PRIMARY KEY(id), CONSTRAINT tickets_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE, CONSTRAINT tickets_participants FOREIGN KEY(participant) REFERENCES participants(id) ON DELETE CASCADE);",
-				"CREATE OR REPLACE VIEW tickets_view AS SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant;",
+				"CREATE TABLE IF NOT EXISTS events (id INTEGER GENERATED ALWAYS AS IDENTITY, name VARCHAR(50) NOT NULL, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, starts_at TIMESTAMP, ends_at TIMESTAMP, version INTEGER NOT NULL DEFAULT 1, PRIMARY KEY(id));",
+				"CREATE TABLE IF NOT EXISTS participants(id INTEGER GENERATED ALWAYS AS IDENTITY, firstname VARCHAR(40) NOT NULL, lastname VARCHAR(40), age NUMERIC(3,0) NOT NULL, version INTEGER NOT NULL DEFAULT 1, CONSTRAINT is_older CHECK(age >= 18), CONSTRAINT is_human CHECK(age < 130), PRIMARY KEY(id));",
+				"CREATE TABLE IF NOT EXISTS tickets(id INTEGER GENERATED ALWAYS AS IDENTITY, event INTEGER NOT NULL, participant INTEGER NOT NULL, version INTEGER NOT NULL DEFAULT 1, PRIMARY KEY(id), CONSTRAINT tickets_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE, CONSTRAINT tickets_participants FOREIGN KEY(participant) REFERENCES participants(id) ON DELETE CASCADE);",
+				"CREATE TABLE IF NOT EXISTS idempotency_keys(principal VARCHAR(255) NOT NULL DEFAULT '', idempotency_key VARCHAR(255) NOT NULL, request_hash CHAR(64) NOT NULL, status INTEGER NOT NULL DEFAULT 0, headers TEXT, body TEXT, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, expires_at TIMESTAMP NOT NULL, PRIMARY KEY(principal, idempotency_key));",
+				"CREATE TABLE IF NOT EXISTS api_keys(id INTEGER GENERATED ALWAYS AS IDENTITY, name VARCHAR(50) NOT NULL, key_hash CHAR(64) NOT NULL, role VARCHAR(20) NOT NULL DEFAULT 'viewer', created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, revoked_at TIMESTAMP NULL DEFAULT NULL, PRIMARY KEY(id), CONSTRAINT api_keys_hash UNIQUE(key_hash));",
+				"CREATE TABLE IF NOT EXISTS event_organizers(event INTEGER NOT NULL, subject VARCHAR(255) NOT NULL, PRIMARY KEY(event, subject), CONSTRAINT event_organizers_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE);",
+				"CREATE OR REPLACE VIEW tickets_view AS SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant;",
 			}
 			for _, stmt := range stmts {
 				if _, err = db.Exec(stmt); err != nil {
-					log.Println(err)
-					return c.String(http.StatusInternalServerError, "Failure when trying to recreate schemas")
+					slog.ErrorContext(c.Request().Context(), "The schema statement failed", "statement", stmt, "error", err)
+					return controllers.Internal("Failure when trying to recreate schemas", err)
 				}
 			}
 
 			for _, mock := range events {
 				if _, err = db.Exec(mock); err != nil {
-					log.Println(err)
+					slog.WarnContext(c.Request().Context(), "The sample row was not inserted", "statement", mock, "error", err)
 				}
 			}
 
 			for _, mock := range participants {
 				if _, err = db.Exec(mock); err != nil {
-					log.Println(err)
+					slog.WarnContext(c.Request().Context(), "The sample row was not inserted", "statement", mock, "error", err)
 				}
 			}
 
 			for _, mock := range tickets {
 				if _, err = db.Exec(mock); err != nil {
-					log.Println(err)
+					slog.WarnContext(c.Request().Context(), "The sample row was not inserted", "statement", mock, "error", err)
 				}
 			}
 
 			return c.String(http.StatusOK, "Database built and established DSN")
 
-		case mysql.Match([]byte(persistence)):
+		case storage.MySQL:
 			err = os.Setenv("dsn", dsn.User+":"+dsn.Password+"@tcp(localhost:3306)/"+dsn.Dbname+"?parseTime=true")
 			if err != nil {
-				return c.String(http.StatusInternalServerError, "Error setting DSN as environment variable")
+				return controllers.Internal("Error setting DSN as environment variable", err)
 			}
 
 			if err = os.Setenv("PERSISTENCE_NAME", "MySQL"); err != nil {
-				c.String(http.StatusInternalServerError, "Error trying to set the PERSISTENCE_NAME as environment variable")
+				return controllers.Internal("Error trying to set the PERSISTENCE_NAME as environment variable", err)
 			}
 
 			db, err := sql.Open("mysql", os.Getenv("dsn"))
 			if err != nil {
-				return c.String(http.StatusInternalServerError, "The DSN is failing or the database connection is dead")
+				return controllers.Internal("The DSN is failing or the database connection is dead", err)
 			}
 			if err = db.Ping(); err != nil {
-				return c.String(http.StatusInternalServerError, "No response from the database")
+				return controllers.Internal("No response from the database", err)
 			}
 			defer func() {
 				if err = db.Close(); err != nil {
@@ -4330,41 +4341,59 @@ func Build() echo.HandlerFunc {
 				"DROP TABLE IF EXISTS events;",
 				"DROP TABLE IF EXISTS participants;",
 				"DROP TABLE IF EXISTS tickets;",
+				"DROP TABLE IF EXISTS idempotency_keys;",
+				"DROP TABLE IF EXISTS api_keys;",
+				"DROP TABLE IF EXISTS event_organizers;",
 				"SET FOREIGN_KEY_CHECKS=1;",
-				"CREATE TABLE IF NOT EXISTS events (id INTEGER AUTO_INCREMENT, name VARCHAR(50) NOT NULL, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY(id));",
-				"CREATE TABLE IF NOT EXISTS participants(id INTEGER AUTO_INCREMENT, firstname VARCHAR(40) NOT NULL, lastname VARCHAR(40), age NUMERIC(3,0) NOT NULL, CONSTRAINT is_older CHECK(age >= 18), CONSTRAINT is_human CHECK(age < 130), PRIMARY KEY(id));",
-				"CREATE TABLE IF NOT EXISTS tickets(id INTEGER AUTO_INCREMENT, event INTEGER NOT NULL, participant INTEGER NOT NULL, PRIMARY KEY(id), CONSTRAINT tickets_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE, CONSTRAINT tickets_participants FOREIGN KEY(participant) REFERENCES participants(id) ON DELETE CASCADE);",
-				"CREATE OR REPLACE VIEW tickets_view AS SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant;",
+				"CREATE TABLE IF NOT EXISTS events (id INTEGER AUTO_INCREMENT, name VARCHAR(50) NOT NULL, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, starts_at TIMESTAMP NULL DEFAULT NULL, ends_at TIMESTAMP NULL DEFAULT NULL, version INTEGER NOT NULL DEFAULT 1, PRIMARY KEY(id));",
+				"CREATE TABLE IF NOT EXISTS participants(id INTEGER AUTO_INCREMENT, firstname VARCHAR(40) NOT NULL, lastname VARCHAR(40), age NUMERIC(3,0) NOT NULL, version INTEGER NOT NULL DEFAULT 1, CONSTRAINT is_older CHECK(age >= 18), CONSTRAINT is_human CHECK(age < 130), PRIMARY KEY(id));",
+				"CREATE TABLE IF NOT EXISTS tickets(id INTEGER AUTO_INCREMENT, event INTEGER NOT NULL, participant INTEGER NOT NULL, version INTEGER NOT NULL DEFAULT 1, PRIMARY KEY(id), CONSTRAINT tickets_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE, CONSTRAINT tickets_participants FOREIGN KEY(participant) REFERENCES participants(id) ON DELETE CASCADE);",
+				"CREATE TABLE IF NOT EXISTS idempotency_keys(principal VARCHAR(255) NOT NULL DEFAULT '', idempotency_key VARCHAR(255) NOT NULL, request_hash CHAR(64) NOT NULL, status INTEGER NOT NULL DEFAULT 0, headers TEXT, body MEDIUMTEXT, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, expires_at TIMESTAMP NOT NULL, PRIMARY KEY(principal, idempotency_key));",
+				"CREATE TABLE IF NOT EXISTS api_keys(id INTEGER AUTO_INCREMENT, name VARCHAR(50) NOT NULL, key_hash CHAR(64) NOT NULL, role VARCHAR(20) NOT NULL DEFAULT 'viewer', created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, revoked_at TIMESTAMP NULL DEFAULT NULL, PRIMARY KEY(id), CONSTRAINT api_keys_hash UNIQUE(key_hash));",
+				"CREATE TABLE IF NOT EXISTS event_organizers(event INTEGER NOT NULL, subject VARCHAR(255) NOT NULL, PRIMARY KEY(event, subject), CONSTRAINT event_organizers_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE);",
+				"CREATE OR REPLACE VIEW tickets_view AS SELECT t.id AS id, CONCAT(p.firstname, ' ',p.lastname) AS participant, e.name AS event, t.version AS version FROM tickets AS t INNER JOIN events AS e ON e.id=t.event INNER JOIN participants AS p ON p.id=t.participant;",
 			}
 			for _, stmt := range stmts {
 				if _, err = db.Exec(stmt); err != nil {
-					log.Println(err)
-					return c.String(http.StatusInternalServerError, "Failure when trying to recreate schemas")
+					slog.ErrorContext(c.Request().Context(), "The schema statement failed", "statement", stmt, "error", err)
+					return controllers.Internal("Failure when trying to recreate schemas", err)
 				}
 			}
 
 			for _, mock := range events {
 				if _, err = db.Exec(mock); err != nil {
-					log.Println(err)
+					slog.WarnContext(c.Request().Context(), "The sample row was not inserted", "statement", mock, "error", err)
 				}
 			}
 
 			for _, mock := range participants {
 				if _, err = db.Exec(mock); err != nil {
-					log.Println(err)
+					slog.WarnContext(c.Request().Context(), "The sample row was not inserted", "statement", mock, "error", err)
 				}
 			}
 
 			for _, mock := range tickets {
 				if _, err = db.Exec(mock); err != nil {
-					log.Println(err)
+					slog.WarnContext(c.Request().Context(), "The sample row was not inserted", "statement", mock, "error", err)
 				}
 			}
 
 			return c.String(http.StatusOK, "Database built and established DSN")
 
 		default:
-			return c.String(http.StatusBadRequest, "The provided persistence doesn't match with none of the available")
+			return controllers.BadRequest("The provided persistence doesn't match with none of the available, postgres or mysql")
 		}
 	}
 }
+
+// persistenceOf the path parameter, e.g. postgres, PostgreSQL, psql or MySQL
+func persistenceOf(name string) (storage.Persistence, bool) {
+	switch strings.ToLower(name) {
+	case "postgres", "postgresql", "psql":
+		return storage.PostgreSQL, true
+	case "mysql":
+		return storage.MySQL, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// ETag of a versioned resource, strong validator
+func ETag(version uint32) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+func SetETag(c echo.Context, version uint32) {
+	c.Response().Header().Set(headerETag, ETag(version))
+}
+
+// NotModified reports whether the If-None-Match header matches the
+// current version, weak comparison as in RFC 7232 section 3.2
+func NotModified(c echo.Context, version uint32) bool {
+	var header = strings.Join(c.Request().Header.Values(headerIfNoneMatch), ",")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == ETag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIfMatch compares the If-Match header with the current version,
+// strong comparison as in RFC 7232 section 3.1
+func CheckIfMatch(c echo.Context, version uint32) error {
+	var header = strings.Join(c.Request().Header.Values(headerIfMatch), ",")
+	if header == "" {
+		if constants.RequireIfMatch {
+			return PreconditionRequired("The If-Match header is required to modify this resource")
+		}
+		return nil
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == ETag(version) {
+			return nil
+		}
+	}
+	return PreconditionFailed("The resource was modified, its current ETag is " + ETag(version))
+}
+
+// Version of a row, sql.ErrNoRows if it doesn't exist
+func Version(ctx context.Context, db database.Preparer, table string, id int) (uint32, error) {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "SELECT version FROM " + table + " WHERE id = $1;"
+	case storage.MySQL:
+		q = "SELECT version FROM " + table + " WHERE id = ?;"
+	}
+
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var version uint32
+	err = stmt.QueryRowContext(ctx, id).Scan(&version)
+	return version, err
+}
+
+// Entity responds with the ETag of the resource, or with 304 if the
+// client already has the current version
+func Entity(c echo.Context, version uint32, data interface{}) error {
+	SetETag(c, version)
+	if NotModified(c, version) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return Respond(c, http.StatusOK, data)
+}
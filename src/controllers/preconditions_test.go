@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  []string
+		required bool
+		status   int // 0 if the request may go on
+	}{
+		{name: "no header", status: 0},
+		{name: "no header, required", required: true, status: http.StatusPreconditionRequired},
+		{name: "current", ifMatch: []string{`"3"`}, status: 0},
+		{name: "current, required", ifMatch: []string{`"3"`}, required: true, status: 0},
+		{name: "stale", ifMatch: []string{`"2"`}, status: http.StatusPreconditionFailed},
+		{name: "stale, required", ifMatch: []string{`"2"`}, required: true, status: http.StatusPreconditionFailed},
+		{name: "any", ifMatch: []string{`*`}, required: true, status: 0},
+		{name: "one of a list", ifMatch: []string{`"1", "3"`}, status: 0},
+		{name: "one of the lines", ifMatch: []string{`"1"`, `"3"`}, status: 0},
+		{name: "none of a list", ifMatch: []string{`"1","2"`}, status: http.StatusPreconditionFailed},
+		// Strong comparison, a weak tag never matches
+		{name: "weak", ifMatch: []string{`W/"3"`}, status: http.StatusPreconditionFailed},
+		{name: "unquoted", ifMatch: []string{`3`}, status: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requireIfMatch(t, tt.required)
+
+			c, _ := request(http.MethodPut, headerIfMatch, tt.ifMatch)
+			if got := statusOf(CheckIfMatch(c, 3)); got != tt.status {
+				t.Errorf("CheckIfMatch(%q) = %d, want %d", tt.ifMatch, got, tt.status)
+			}
+		})
+	}
+}
+
+func TestCheckIfMatchDetail(t *testing.T) {
+	requireIfMatch(t, false)
+
+	c, _ := request(http.MethodPut, headerIfMatch, []string{`"2"`})
+
+	var failed *PreconditionFailedError
+	if err := CheckIfMatch(c, 3); !errors.As(err, &failed) || failed.Detail != `The resource was modified, its current ETag is "3"` {
+		t.Errorf("CheckIfMatch() = %v, want the current ETag in the detail", err)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch []string
+		want        bool
+	}{
+		{name: "no header", want: false},
+		{name: "current", ifNoneMatch: []string{`"3"`}, want: true},
+		{name: "stale", ifNoneMatch: []string{`"2"`}, want: false},
+		// Weak comparison, a weak tag matches its strong one
+		{name: "weak", ifNoneMatch: []string{`W/"3"`}, want: true},
+		{name: "weak stale", ifNoneMatch: []string{`W/"2"`}, want: false},
+		{name: "any", ifNoneMatch: []string{`*`}, want: true},
+		{name: "one of a list", ifNoneMatch: []string{`"1", W/"3"`}, want: true},
+		{name: "one of the lines", ifNoneMatch: []string{`"1"`, `"3"`}, want: true},
+		{name: "unquoted", ifNoneMatch: []string{`3`}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := request(http.MethodGet, headerIfNoneMatch, tt.ifNoneMatch)
+			if got := NotModified(c, 3); got != tt.want {
+				t.Errorf("NotModified(%q) = %v, want %v", tt.ifNoneMatch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntity(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch []string
+		status      int
+	}{
+		{name: "no header", status: http.StatusOK},
+		{name: "current", ifNoneMatch: []string{`"3"`}, status: http.StatusNotModified},
+		{name: "stale", ifNoneMatch: []string{`"2"`}, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := request(http.MethodGet, headerIfNoneMatch, tt.ifNoneMatch)
+
+			if err := Entity(c, 3, map[string]string{"name": "Fest"}); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			// The 304 carries the ETag too, RFC 7232 section 4.1
+			if got := rec.Header().Get(headerETag); got != `"3"` {
+				t.Errorf("ETag = %q, want \"3\"", got)
+			}
+			if tt.status == http.StatusNotModified && rec.Body.Len() > 0 {
+				t.Errorf("The 304 has a body: %s", rec.Body)
+			}
+		})
+	}
+}
+
+func request(method, header string, values []string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/api/v1/event/1", nil)
+	for _, v := range values {
+		req.Header.Add(header, v)
+	}
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func requireIfMatch(t *testing.T, required bool) {
+	previous := constants.RequireIfMatch
+	constants.RequireIfMatch = required
+	t.Cleanup(func() { constants.RequireIfMatch = previous })
+}
+
+// statusOf the error as the problems render it, 0 if nil
+func statusOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	return asProblem(err).status
+}
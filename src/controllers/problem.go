@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/logging"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+type problem struct {
+	status int
+	detail string
+	fields []models.FieldError
+}
+
+// HTTPErrorHandler replaces the echo default one, errors are rendered as
+// application/problem+json unless the client explicitly asks for
+// application/json, in which case the legacy envelope is preserved.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		// A stream can't change its status anymore, see ListWriter
+		if c.Response().Header().Get(headerTrailer) == headerStreamError {
+			p := problemOf(c, err)
+			c.Response().Header().Set(headerStreamError, strconv.Itoa(p.status)+" "+p.detail)
+		}
+		return
+	}
+
+	var p = problemOf(c, err)
+
+	switch {
+	case c.Request().Method == http.MethodHead:
+		err = c.NoContent(p.status)
+	case prefersEnvelope(c.Request()):
+		err = c.JSON(p.status, badResponse(c, p))
+	default:
+		err = writeProblem(c, p)
+	}
+
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "The error response could not be written", "error", err)
+	}
+}
+
+// Detail of the requests that didn't finish before their deadline
+const timeoutDetail = "The request took longer than its deadline and was canceled, try again or ask for less"
+
+// problemOf the error of a request. The drivers don't always return
+// context.DeadlineExceeded once the deadline passes, lib/pq cancels the
+// query, so the internal errors after it are timeouts too.
+func problemOf(c echo.Context, err error) problem {
+	var p = asProblem(err)
+	if p.status == http.StatusInternalServerError && errors.Is(c.Request().Context().Err(), context.DeadlineExceeded) {
+		return problem{status: http.StatusGatewayTimeout, detail: timeoutDetail}
+	}
+	return p
+}
+
+// The only place where an error becomes a status code
+func asProblem(err error) problem {
+	var item *BatchItemError
+	if errors.As(err, &item) {
+		p := asProblem(item.Err)
+		p.detail = "Item " + strconv.Itoa(item.Index) + ": " + p.detail
+		for i, f := range p.fields {
+			p.fields[i].Field = "[" + strconv.Itoa(item.Index) + "]." + f.Field
+		}
+		return p
+	}
+
+	var (
+		badRequest *BadRequestError
+		validation *ValidationError
+		notFound   *NotFoundError
+		unauth     *UnauthorizedError
+		forbidden  *ForbiddenError
+		conflict   *ConflictError
+		media      *UnsupportedMediaTypeError
+		failed     *PreconditionFailedError
+		required   *PreconditionRequiredError
+		tooMany    *TooManyRequestsError
+		tooLarge   *TooLargeError
+		internal   *InternalError
+		he         *echo.HTTPError
+	)
+
+	switch {
+	case errors.As(err, &badRequest):
+		return problem{status: http.StatusBadRequest, detail: badRequest.Detail}
+	case errors.As(err, &validation):
+		return problem{status: http.StatusUnprocessableEntity, detail: validation.Detail, fields: validation.Fields}
+	case errors.As(err, &notFound):
+		return problem{status: http.StatusNotFound, detail: notFound.Detail}
+	case errors.As(err, &unauth):
+		return problem{status: http.StatusUnauthorized, detail: unauth.Detail}
+	case errors.As(err, &forbidden):
+		return problem{status: http.StatusForbidden, detail: forbidden.Detail}
+	case errors.As(err, &conflict):
+		return problem{status: http.StatusConflict, detail: conflict.Detail}
+	case errors.As(err, &media):
+		return problem{status: http.StatusUnsupportedMediaType, detail: media.Detail}
+	case errors.As(err, &failed):
+		return problem{status: http.StatusPreconditionFailed, detail: failed.Detail}
+	case errors.As(err, &required):
+		return problem{status: http.StatusPreconditionRequired, detail: required.Detail}
+	case errors.As(err, &tooMany):
+		return problem{status: http.StatusTooManyRequests, detail: tooMany.Detail}
+	case errors.As(err, &tooLarge):
+		return problem{status: http.StatusRequestEntityTooLarge, detail: tooLarge.Detail}
+	case errors.Is(err, context.DeadlineExceeded):
+		return problem{status: http.StatusGatewayTimeout, detail: timeoutDetail}
+	case errors.As(err, &internal):
+		return problem{status: http.StatusInternalServerError, detail: internal.Detail}
+	case errors.As(err, &he):
+		if inner, ok := he.Internal.(*echo.HTTPError); ok {
+			he = inner
+		}
+		p := problem{status: he.Code}
+		if m, ok := he.Message.(string); ok && m != http.StatusText(he.Code) {
+			p.detail = m
+		}
+		return p
+	default:
+		return problem{status: http.StatusInternalServerError}
+	}
+}
+
+func prefersEnvelope(r *http.Request) bool {
+	accept := r.Header.Get(echo.HeaderAccept)
+
+	return strings.Contains(accept, echo.MIMEApplicationJSON) &&
+		!strings.Contains(accept, constants.MIMEApplicationProblemJSON)
+}
+
+func writeProblem(c echo.Context, p problem) error {
+	var res = c.Response()
+
+	res.Header().Set(echo.HeaderContentType, constants.MIMEApplicationProblemJSON)
+	res.WriteHeader(p.status)
+
+	return json.NewEncoder(res).Encode(p.render(c))
+}
+
+// ProblemOf renders an error as the HTTPErrorHandler would, for responses
+// that were already committed such as streams
+func ProblemOf(c echo.Context, err error) models.Problem {
+	return problemOf(c, err).render(c)
+}
+
+// StatusOf is the status code, detail and invalid fields of an error, for
+// the protocols that don't render problems such as GraphQL
+func StatusOf(err error) (int, string, []models.FieldError) {
+	p := asProblem(err)
+	if p.detail == "" {
+		p.detail = http.StatusText(p.status)
+	}
+	return p.status, p.detail, p.fields
+}
+
+func (p problem) render(c echo.Context) models.Problem {
+	return models.Problem{
+		Type:      problemType(p.status),
+		Title:     http.StatusText(p.status),
+		Status:    p.status,
+		Detail:    p.detail,
+		Instance:  c.Request().URL.String(),
+		Errors:    p.fields,
+		RequestID: logging.RequestID(c.Request().Context()),
+	}
+}
+
+func badResponse(c echo.Context, p problem) models.BadResponse {
+	var (
+		title   = http.StatusText(p.status)
+		message = p.detail
+		errs    = make([]map[string]interface{}, 0, len(p.fields)+1)
+	)
+
+	if message == "" {
+		message = title
+	}
+	errs = append(errs, map[string]interface{}{
+		"reason":  title,
+		"message": message,
+	})
+	for _, f := range p.fields {
+		errs = append(errs, map[string]interface{}{
+			"reason":  f.Field,
+			"message": f.Message,
+		})
+	}
+
+	return models.BadResponse{
+		APIVersion: constants.APIVersion,
+		Method:     methodName(c),
+		Context:    c.Request().URL.String(),
+		Params:     params(c),
+		RequestID:  logging.RequestID(c.Request().Context()),
+		Error: models.Error{
+			Code:    uint16(p.status),
+			Message: title,
+			Errors:  errs,
+		},
+	}
+}
+
+// Type URI of a problem, e.g. /problems/not-found
+func problemType(status int) string {
+	slug := strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "-"))
+	if slug == "" {
+		return "about:blank"
+	}
+	return constants.ProblemTypeBase + slug
+}
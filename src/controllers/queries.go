@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/luisnquin/restapi-technical-test/src/database"
+)
+
+func Count(ctx context.Context, db database.Preparer, q string, args ...interface{}) (int, error) {
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var n int
+	err = stmt.QueryRowContext(ctx, args...).Scan(&n)
+	return n, err
+}
+
+func Exists(ctx context.Context, db database.Preparer, q string, args ...interface{}) (bool, error) {
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	var exists bool
+	err = stmt.QueryRowContext(ctx, args...).Scan(&exists)
+	return exists, err
+}
+
+// Exec runs a single statement and returns the number of affected rows
+func Exec(ctx context.Context, db database.Preparer, q string, args ...interface{}) (int64, error) {
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	r, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
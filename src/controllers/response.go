@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+)
+
+// Respond writes the successful envelope, method and params are taken from the route
+func Respond(c echo.Context, status int, data interface{}) error {
+	return c.JSON(status, envelope(c, data))
+}
+
+func envelope(c echo.Context, data interface{}) models.SuccessfulResponse {
+	return models.SuccessfulResponse{
+		APIVersion: constants.APIVersion,
+		Context:    c.Request().URL.String(),
+		Method:     methodName(c),
+		Params:     params(c),
+		Data:       data,
+	}
+}
+
+// Name given to the route in the routers package, e.g. events.create
+func methodName(c echo.Context) string {
+	var (
+		method = c.Request().Method
+		path   = c.Path()
+	)
+
+	for _, r := range c.Echo().Routes() {
+		if r.Method == method && r.Path == path {
+			return r.Name
+		}
+	}
+	return ""
+}
+
+func params(c echo.Context) map[string]interface{} {
+	var names = c.ParamNames()
+	if len(names) == 0 {
+		return nil
+	}
+
+	var (
+		values = c.ParamValues()
+		m      = make(map[string]interface{}, len(names))
+	)
+
+	for i, name := range names {
+		if i >= len(values) {
+			break
+		}
+		name = strings.ReplaceAll(name, "-", "_")
+		if n, err := strconv.Atoi(values[i]); err == nil {
+			m[name] = n
+		} else {
+			m[name] = values[i]
+		}
+	}
+	return m
+}
+
+// Created responds with 201 and the Location header pointing to the given route
+func Created(c echo.Context, route string, id interface{}, data interface{}) error {
+	c.Response().Header().Set(echo.HeaderLocation, c.Echo().Reverse(route, id))
+	return Respond(c, http.StatusCreated, data)
+}
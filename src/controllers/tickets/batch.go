@@ -0,0 +1,174 @@
+package tickets
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// NewBatch registers an array of participants to events within a single
+// transaction, e.g. all the attendees of an event at once
+func NewBatch() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			db      = storage.Get(constants.Persistence)
+			request []models.Ticket
+		)
+
+		atomic, err := controllers.Atomic(c)
+		if err != nil {
+			return err
+		}
+
+		n, err := controllers.DecodeBatch(c, &request)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "INSERT INTO tickets(participant, event) VALUES($1, $2) RETURNING id, version;"
+		case storage.MySQL:
+			q = "INSERT INTO tickets(participant, event) VALUES(?, ?);"
+		}
+
+		ctx := c.Request().Context()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return controllers.Internal("The transaction could not be started", err)
+		}
+		defer tx.Rollback()
+
+		stmt, err := tx.PrepareContext(ctx, q)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = stmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		// Counted once committed
+		var created int
+		err = controllers.Batch(ctx, c, tx, atomic, n, http.StatusCreated, func(ctx context.Context, i int) (int, interface{}, error) {
+			var ticket = request[i]
+
+			// The same checks as a single ticket, inside the savepoint of the item
+			if err := repository.CheckTicket(ctx, tx, int64(ticket.Event), int64(ticket.Participant), 0); err != nil {
+				return 0, nil, err
+			}
+
+			switch constants.Persistence {
+			case storage.PostgreSQL:
+				if err := stmt.QueryRowContext(ctx, ticket.Participant, ticket.Event).Scan(&ticket.Id, &ticket.Version); err != nil {
+					return 0, nil, controllers.Rejected(ctx, err, controllers.BadRequest("The ticket was rejected, not valid"))
+				}
+
+			case storage.MySQL:
+				r, err := stmt.ExecContext(ctx, ticket.Participant, ticket.Event)
+				if err != nil {
+					return 0, nil, controllers.Rejected(ctx, err, controllers.BadRequest("The ticket was rejected, not valid"))
+				}
+
+				id, err := r.LastInsertId()
+				if err != nil {
+					return 0, nil, controllers.Internal("The ID of the new ticket could not be retrieved", err)
+				}
+				ticket.Id, ticket.Version = uint32(id), 1 // Default version
+			}
+
+			created++
+			return http.StatusCreated, ticket, nil
+		})
+		if err == nil {
+			metrics.TicketsCreated.Add(float64(created))
+		}
+		return err
+	}
+}
+
+// RemoveBatch deletes an array of tickets given their IDs
+func RemoveBatch() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			db      = storage.Get(constants.Persistence)
+			request []uint32
+		)
+
+		atomic, err := controllers.Atomic(c)
+		if err != nil {
+			return err
+		}
+
+		n, err := controllers.DecodeBatch(c, &request)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
+		}
+		defer func() {
+			if err = db.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		var q string
+		switch constants.Persistence {
+		case storage.PostgreSQL:
+			q = "DELETE FROM tickets WHERE id = $1;"
+		case storage.MySQL:
+			q = "DELETE FROM tickets WHERE id = ?;"
+		}
+
+		ctx := c.Request().Context()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return controllers.Internal("The transaction could not be started", err)
+		}
+		defer tx.Rollback()
+
+		stmt, err := tx.PrepareContext(ctx, q)
+		if err != nil {
+			return controllers.Internal("The statement could not be prepared", err)
+		}
+		defer func() {
+			if err = stmt.Close(); err != nil {
+				panic(err)
+			}
+		}()
+
+		return controllers.Batch(ctx, c, tx, atomic, n, http.StatusOK, func(ctx context.Context, i int) (int, interface{}, error) {
+			r, err := stmt.ExecContext(ctx, request[i])
+			if err != nil {
+				return 0, nil, controllers.Internal("The ticket could not be deleted", err)
+			}
+			if n, _ := r.RowsAffected(); n == 0 {
+				return 0, nil, controllers.NotFound("Ticket not found")
+			}
+			return http.StatusOK, nil, nil
+		})
+	}
+}
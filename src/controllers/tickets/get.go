@@ -1,14 +1,12 @@
 package tickets
 
 import (
-	"context"
-	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
 	"github.com/luisnquin/restapi-technical-test/src/models"
 	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
@@ -22,22 +20,13 @@ func FetchTickets() echo.HandlerFunc {
 
 		desc, _ := strconv.ParseBool(c.QueryParam("desc"))
 
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		page, err := controllers.Page(c)
+		if err != nil {
+			return err
+		}
+
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 		defer func() {
 			if err = db.Close(); err != nil {
@@ -45,32 +34,28 @@ func FetchTickets() echo.HandlerFunc {
 			}
 		}()
 
-		var q string
-		if desc {
-			q = "SELECT * FROM tickets_view ORDER BY id DESC;"
-		} else {
-			q = "SELECT * FROM tickets_view;"
+		ctx := c.Request().Context()
+
+		page.Total, err = controllers.Count(ctx, db, "SELECT COUNT(*) FROM tickets_view;")
+		if err != nil {
+			return controllers.Internal("The tickets could not be counted", err)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		var q string
+		switch {
+		case constants.Persistence == storage.PostgreSQL && desc:
+			q = "SELECT id, participant, event, version FROM tickets_view ORDER BY id DESC LIMIT $1 OFFSET $2;"
+		case constants.Persistence == storage.MySQL && desc:
+			q = "SELECT id, participant, event, version FROM tickets_view ORDER BY id DESC LIMIT ? OFFSET ?;"
+		case constants.Persistence == storage.PostgreSQL:
+			q = "SELECT id, participant, event, version FROM tickets_view ORDER BY id ASC LIMIT $1 OFFSET $2;"
+		case constants.Persistence == storage.MySQL:
+			q = "SELECT id, participant, event, version FROM tickets_view ORDER BY id ASC LIMIT ? OFFSET ?;"
+		}
 
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
 			if err = stmt.Close(); err != nil {
@@ -78,61 +63,31 @@ func FetchTickets() echo.HandlerFunc {
 			}
 		}()
 
-		rows, err := stmt.QueryContext(ctx)
+		rows, err := stmt.QueryContext(ctx, page.Limit, page.Offset)
 		if err != nil {
-			return c.JSON(http.StatusNotFound, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-				Error: models.Error{
-					Code:    404,
-					Message: "Not Found",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Not Found",
-							"message": "There was an error when tried to bring the payload",
-						},
-					},
-				},
-			})
+			return controllers.Internal("There was an error when tried to bring the payload", err)
 		}
+		defer func() {
+			if err = rows.Close(); err != nil {
+				panic(err)
+			}
+		}()
 
-		var tviews models.TicketViews
-
+		var list = controllers.NewList(c, page, "tickets", models.TicketView{})
 		for rows.Next() {
 			var tview models.TicketView
-			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event); err != nil {
-				return c.JSON(http.StatusConflict, models.BadResponse{
-					APIVersion: constants.APIVersion,
-					Method:     "tickets.get",
-					Context:    c.Request().URL.String(),
-					Error: models.Error{
-						Code:    409,
-						Message: "Conflict",
-						Errors: []map[string]interface{}{
-							{
-								"reason": "Conflict",
-								"message": "An error was logged while trying to process the payload",
-							},
-						},
-					},
-				})
+			if err = rows.Scan(&tview.Id, &tview.Participant, &tview.Event, &tview.Version); err != nil {
+				return controllers.Internal("An error was logged while trying to process the payload", err)
+			}
+			if err = list.Add(tview); err != nil {
+				return err
 			}
-			tviews = append(tviews, tview)
 		}
-		if len(tviews) == 0 {
-			return c.JSON(http.StatusNoContent, models.SuccessfulResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-			})
+		if err = rows.Err(); err != nil {
+			return controllers.Internal("An error was logged while trying to process the payload", err)
 		}
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "tickets.get",
-			Context:    c.Request().URL.String(),
-			Data:       tviews,
-		})
+
+		return list.Close()
 	}
 }
 
@@ -143,46 +98,12 @@ func FetchById() echo.HandlerFunc {
 			err error
 		)
 
-		id, err := strconv.Atoi(c.Param("id"))
+		id, err := controllers.IntParam(c, "id")
 		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    422,
-					Message: "Unprocessable Entity",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Unprocessable Entity",
-							"message": "The ID parameter cannot be processed as integer",
-						},
-					},
-				},
-			})
+			return err
 		}
-		if err = db.Connect(); err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-							"message": "Database connection failed",
-						},
-					},
-				},
-			})
+		if err = db.Connect(c.Request().Context()); err != nil {
+			return controllers.Internal("Database connection failed", err)
 		}
 
 		defer func() {
@@ -194,33 +115,16 @@ func FetchById() echo.HandlerFunc {
 		var q string
 		switch constants.Persistence {
 		case storage.PostgreSQL:
-			q = "SELECT * FROM tickets_view WHERE id = $1;"
+			q = "SELECT id, participant, event, version FROM tickets_view WHERE id = $1;"
 		case storage.MySQL:
-			q = "SELECT * FROM tickets_view WHERE id = ?;"
+			q = "SELECT id, participant, event, version FROM tickets_view WHERE id = ?;"
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
+		ctx := c.Request().Context()
 
 		stmt, err := db.PrepareContext(ctx, q)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    500,
-					Message: "Internal Server Error",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Internal Server Error",
-						},
-					},
-				},
-			})
+			return controllers.Internal("The statement could not be prepared", err)
 		}
 		defer func() {
 			if err = stmt.Close(); err != nil {
@@ -229,36 +133,11 @@ func FetchById() echo.HandlerFunc {
 		}()
 
 		var tview models.TicketView
-		err = stmt.QueryRowContext(ctx, id).Scan(&tview.Id, &tview.Participant, &tview.Event)
+		err = stmt.QueryRowContext(ctx, id).Scan(&tview.Id, &tview.Participant, &tview.Event, &tview.Version)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, models.BadResponse{
-				APIVersion: constants.APIVersion,
-				Method:     "tickets.get",
-				Context:    c.Request().URL.String(),
-				Params: map[string]interface{}{
-					"id": id,
-				},
-				Error: models.Error{
-					Code:    400,
-					Message: "Bad Request",
-					Errors: []map[string]interface{}{
-						{
-							"reason":  "Bad Request",
-							"message": "The ID paremeter was rejected, not valid",
-						},
-					},
-				},
-			})
+			return controllers.Rejected(ctx, err, controllers.BadRequest("The ID paremeter was rejected, not valid"))
 		}
 
-		return c.JSON(http.StatusOK, models.SuccessfulResponse{
-			APIVersion: constants.APIVersion,
-			Method:     "tickets.get",
-			Context:    c.Request().URL.String(),
-			Params: map[string]interface{}{
-				"id": id,
-			},
-			Data: tview,
-		})
+		return controllers.Entity(c, tview.Version, tview)
 	}
 }
@@ -6,8 +6,15 @@ import (
 )
 
 type Connecter interface {
-	Connect() error
+	Connect(ctx context.Context) error
 	Close() error
 	Prepare(stmt string) (*sql.Stmt, error)
 	PrepareContext(ctx context.Context, stmt string) (*sql.Stmt, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Preparer is a connection or a transaction, for the statements that may
+// run in either
+type Preparer interface {
+	PrepareContext(ctx context.Context, stmt string) (*sql.Stmt, error)
 }
@@ -2,6 +2,9 @@ SET FOREIGN_KEY_CHECKS=0;
 DROP TABLE IF EXISTS events;
 DROP TABLE IF EXISTS participants;
 DROP TABLE IF EXISTS tickets;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS event_organizers;
 SET FOREIGN_KEY_CHECKS=1;
 
 
@@ -9,6 +12,9 @@ CREATE TABLE IF NOT EXISTS events (
     id INTEGER AUTO_INCREMENT, 
     name VARCHAR(50) NOT NULL, 
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, 
+    starts_at TIMESTAMP NULL DEFAULT NULL, 
+    ends_at TIMESTAMP NULL DEFAULT NULL, 
+    version INTEGER NOT NULL DEFAULT 1, 
     PRIMARY KEY(id)
 );
 CREATE TABLE IF NOT EXISTS participants(
@@ -16,6 +22,7 @@ CREATE TABLE IF NOT EXISTS participants(
     firstname VARCHAR(40) NOT NULL, 
     lastname VARCHAR(40), 
     age NUMERIC(3,0) NOT NULL, 
+    version INTEGER NOT NULL DEFAULT 1, 
     CONSTRAINT is_older CHECK(age >= 18), 
     CONSTRAINT is_human CHECK(age < 130), 
     PRIMARY KEY(id)
@@ -24,15 +31,47 @@ CREATE TABLE IF NOT EXISTS participants(
 CREATE TABLE IF NOT EXISTS tickets(
     id INTEGER AUTO_INCREMENT, event INTEGER NOT NULL, 
     participant INTEGER NOT NULL, 
+    version INTEGER NOT NULL DEFAULT 1, 
     PRIMARY KEY(id), 
     CONSTRAINT tickets_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE, 
     CONSTRAINT tickets_participants FOREIGN KEY(participant) REFERENCES participants(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+    principal VARCHAR(255) NOT NULL DEFAULT '',
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    headers TEXT,
+    body MEDIUMTEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(principal, idempotency_key)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys(
+    id INTEGER AUTO_INCREMENT,
+    name VARCHAR(50) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'viewer',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY(id),
+    CONSTRAINT api_keys_hash UNIQUE(key_hash)
+);
+
+CREATE TABLE IF NOT EXISTS event_organizers(
+    event INTEGER NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    PRIMARY KEY(event, subject),
+    CONSTRAINT event_organizers_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE
+);
+
 CREATE OR REPLACE VIEW tickets_view AS 
     SELECT t.id AS id, 
     CONCAT(p.firstname, ' ',p.lastname) AS participant, 
-    e.name AS event 
+    e.name AS event, 
+    t.version AS version 
 FROM tickets AS t 
 INNER JOIN events AS e ON e.id=t.event 
 INNER JOIN participants AS p ON p.id=t.participant;
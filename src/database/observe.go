@@ -0,0 +1,310 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+)
+
+// The drivers of Connect, the ones of lib/pq and go-sql-driver/mysql with
+// every statement logged and traced, see observe
+const (
+	driverPostgreSQL = "postgres-observed"
+	driverMySQL      = "mysql-observed"
+)
+
+// Tracer of the connections and statements, the global provider is set
+// after it's created
+var tracer = otel.Tracer("github.com/luisnquin/restapi-technical-test/src/database")
+
+func init() {
+	sql.Register(driverPostgreSQL, observedDriver{dialect: "postgresql", Driver: pqDriver{}})
+	sql.Register(driverMySQL, observedDriver{dialect: "mysql", Driver: &mysql.MySQLDriver{}})
+}
+
+// observe a statement of the dialect, the returned function is called
+// with its outcome. The queries are logged at debug level, with the ID of
+// the request of the context, their latency is measured and they have a
+// span, child of the one of the request.
+func observe(ctx context.Context, dialect, op, query string) func(err error) {
+	var start = time.Now()
+
+	_, span := tracer.Start(ctx, "sql."+op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", dialect),
+		attribute.String("db.operation", op),
+		attribute.String("db.statement", query),
+	))
+
+	return func(err error) {
+		var (
+			d      = time.Since(start)
+			failed = err != nil && err != driver.ErrSkip
+		)
+		metrics.ObserveQuery(dialect, op, query, d, failed)
+		end(span, err, failed)
+
+		var attrs = []slog.Attr{
+			slog.String("dialect", dialect),
+			slog.String("op", op),
+			slog.String("statement", query),
+			slog.Duration("duration", d),
+		}
+		if failed {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		slog.LogAttrs(ctx, slog.LevelDebug, "query", attrs...)
+	}
+}
+
+func end(span trace.Span, err error, failed bool) {
+	if failed {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// pqDriver opens the connections of lib/pq with its Connector, which dials
+// within the context, the Driver doesn't
+type pqDriver struct {
+	pq.Driver
+}
+
+func (pqDriver) OpenConnector(name string) (driver.Connector, error) {
+	return pq.NewConnector(name)
+}
+
+type observedDriver struct {
+	driver.Driver
+	dialect string
+}
+
+func (d observedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &observedConn{Conn: conn, dialect: d.dialect}, nil
+}
+
+// OpenConnector lets database/sql open the connections with the context of
+// the query that needs them, so connecting has its own span
+func (d observedDriver) OpenConnector(name string) (driver.Connector, error) {
+	var c = &observedConnector{driver: d, name: name}
+
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		c.connector = connector
+	}
+	return c, nil
+}
+
+// observedConnector of a driver, connector is nil if the driver opens the
+// connections by name only
+type observedConnector struct {
+	driver    observedDriver
+	connector driver.Connector
+	name      string
+}
+
+func (c *observedConnector) Connect(ctx context.Context) (conn driver.Conn, err error) {
+	_, span := tracer.Start(ctx, "sql.connect", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", c.driver.dialect),
+	))
+	defer func() { end(span, err, err != nil) }()
+
+	type opened struct {
+		conn driver.Conn
+		err  error
+	}
+
+	// lib/pq only dials within the context, the startup waits for the
+	// server as long as it takes, so the connection is left behind and
+	// closed once it's done if the context ends first
+	var done = make(chan opened, 1)
+	go func() {
+		var o opened
+		if c.connector == nil {
+			o.conn, o.err = c.driver.Open(c.name)
+		} else {
+			o.conn, o.err = c.connector.Connect(ctx)
+		}
+		done <- o
+	}()
+
+	select {
+	case o := <-done:
+		if o.err != nil {
+			return nil, o.err
+		}
+		if _, ok := o.conn.(*observedConn); ok {
+			return o.conn, nil
+		}
+		return &observedConn{Conn: o.conn, dialect: c.driver.dialect}, nil
+	case <-ctx.Done():
+		go func() {
+			if o := <-done; o.conn != nil {
+				o.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *observedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// observedConn forwards the optional interfaces of the drivers, the ones
+// missing in the driver fall back as database/sql does
+type observedConn struct {
+	driver.Conn
+	dialect string
+}
+
+func (c *observedConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	done := observe(ctx, c.dialect, "prepare", query)
+	defer func() { done(err) }()
+
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &observedStmt{Stmt: stmt, dialect: c.dialect, query: query}, nil
+}
+
+func (c *observedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *observedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() // Fallback of database/sql too
+}
+
+func (c *observedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (r driver.Result, err error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done := observe(ctx, c.dialect, "exec", query)
+	defer func() { done(err) }()
+	return e.ExecContext(ctx, query, args)
+}
+
+func (c *observedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done := observe(ctx, c.dialect, "query", query)
+	defer func() { done(err) }()
+	return q.QueryContext(ctx, query, args)
+}
+
+func (c *observedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+func (c *observedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+type observedStmt struct {
+	driver.Stmt
+	dialect string
+	query   string
+}
+
+func (s *observedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (r driver.Result, err error) {
+	done := observe(ctx, s.dialect, "exec", s.query)
+	defer func() { done(err) }()
+
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+	values, err := valuesOf(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values) // Fallback of database/sql too
+}
+
+func (s *observedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	done := observe(ctx, s.dialect, "query", s.query)
+	defer func() { done(err) }()
+
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return q.QueryContext(ctx, args)
+	}
+	values, err := valuesOf(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values) // Fallback of database/sql too
+}
+
+func (s *observedStmt) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+func (s *observedStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if c, ok := s.Stmt.(driver.ColumnConverter); ok { // go-sql-driver/mysql implements it
+		return c.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+func valuesOf(args []driver.NamedValue) ([]driver.Value, error) {
+	var values = make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, driver.ErrSkip
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
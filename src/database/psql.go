@@ -5,26 +5,31 @@ import (
 	"database/sql"
 	"os"
 
-	_ "github.com/lib/pq"
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
 )
 
 type PostgreSQL struct {
 	Db *sql.DB
 }
 
-func (db *PostgreSQL) Connect() error {
-	conn, err := sql.Open("postgres", os.Getenv("dsn"))
+// Connect opens a pool and checks it within the context, the one of the
+// request that needs it
+func (db *PostgreSQL) Connect(ctx context.Context) error {
+	conn, err := sql.Open(driverPostgreSQL, os.Getenv("dsn"))
 	if err != nil {
 		return err
 	}
-	if err = conn.Ping(); err != nil {
+	if err = conn.PingContext(ctx); err != nil {
+		conn.Close()
 		return err
 	}
 	db.Db = conn
+	metrics.TrackDB(conn)
 	return nil
 }
 
 func (db *PostgreSQL) Close() error {
+	metrics.UntrackDB(db.Db)
 	return db.Db.Close()
 }
 
@@ -35,3 +40,7 @@ func (db *PostgreSQL) Prepare(stmt string) (*sql.Stmt, error) {
 func (db *PostgreSQL) PrepareContext(ctx context.Context, stmt string) (*sql.Stmt, error) {
 	return db.Db.PrepareContext(ctx, stmt)
 }
+
+func (db *PostgreSQL) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return db.Db.BeginTx(ctx, opts)
+}
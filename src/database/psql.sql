@@ -5,6 +5,9 @@ CREATE TABLE IF NOT EXISTS events (
     id INTEGER GENERATED ALWAYS AS IDENTITY,
     name VARCHAR(50) NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
     PRIMARY KEY(id)
 );
 
@@ -13,6 +16,7 @@ CREATE TABLE IF NOT EXISTS participants(
     firstname VARCHAR(40) NOT NULL,
     lastname VARCHAR(40),
     age NUMERIC(3,0) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
     CONSTRAINT is_older CHECK(age >= 18),
     CONSTRAINT is_human CHECK(age < 130),
     PRIMARY KEY(id)
@@ -22,16 +26,48 @@ CREATE TABLE IF NOT EXISTS tickets(
     id INTEGER GENERATED ALWAYS AS IDENTITY,
     event INTEGER NOT NULL,
     participant INTEGER NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
     PRIMARY KEY(id),
     CONSTRAINT tickets_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE,
     CONSTRAINT tickets_participants FOREIGN KEY(participant) REFERENCES participants(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+    principal VARCHAR(255) NOT NULL DEFAULT '',
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    headers TEXT,
+    body TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(principal, idempotency_key)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys(
+    id INTEGER GENERATED ALWAYS AS IDENTITY,
+    name VARCHAR(50) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'viewer',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY(id),
+    CONSTRAINT api_keys_hash UNIQUE(key_hash)
+);
+
+CREATE TABLE IF NOT EXISTS event_organizers(
+    event INTEGER NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    PRIMARY KEY(event, subject),
+    CONSTRAINT event_organizers_event FOREIGN KEY(event) REFERENCES events(id) ON DELETE CASCADE
+);
+
 CREATE OR REPLACE VIEW tickets_view AS 
     SELECT
     t.id AS id, 
     CONCAT(p.firstname, ' ',p.lastname) AS participant,
-    e.name AS event
+    e.name AS event,
+    t.version AS version
     FROM tickets AS t 
 INNER JOIN events AS e ON e.id=t.event 
 INNER JOIN participants AS p ON p.id=t.participant;
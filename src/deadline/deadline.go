@@ -0,0 +1,29 @@
+// Package deadline has the time each kind of route has to answer. The
+// queries of a request run in its context, they are canceled once the
+// deadline passes or the client goes away, and the request fails with 504.
+package deadline
+
+import (
+	"os"
+	"time"
+)
+
+// The deadlines of each kind of route, a duration in the environment, e.g.
+// DEADLINE_COLLECTION=30s
+var (
+	Item       = FromEnv("DEADLINE_ITEM", time.Second*5)        // Get, create, replace, modify or delete a resource
+	Collection = FromEnv("DEADLINE_COLLECTION", time.Second*10) // Pages, exports, calendars and GraphQL queries
+	Batch      = FromEnv("DEADLINE_BATCH", time.Second*10)      // The :batch routes, every item in a transaction
+	Import     = FromEnv("DEADLINE_IMPORT", time.Minute)        // The uploaded files
+	Check      = FromEnv("DEADLINE_CHECK", time.Second*3)       // API keys, idempotency keys and readiness
+)
+
+// FromEnv reads the deadline from the variable, the default is kept if
+// it's unset or not a positive duration
+func FromEnv(name string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
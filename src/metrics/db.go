@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TrackDB adds the stats of the pool to the db_* metrics until it's
+// untracked, see database.Connect
+func TrackDB(db *sql.DB) {
+	pools.track(db)
+}
+
+// UntrackDB before the pool is closed, its counters are kept
+func UntrackDB(db *sql.DB) {
+	pools.untrack(db)
+}
+
+var pools = newPoolCollector()
+
+func init() {
+	prometheus.MustRegister(pools)
+}
+
+// poolCollector sums the sql.DBStats of the open pools, the handlers
+// open one per request. The counters include the closed pools.
+type poolCollector struct {
+	mu     sync.Mutex
+	open   map[*sql.DB]struct{}
+	opened uint64
+	closed sql.DBStats // Only the counters
+
+	pools, openedTotal, connections, inUse, idle        *prometheus.Desc
+	waitCount, waitDuration                             *prometheus.Desc
+	maxIdleClosed, maxIdleTimeClosed, maxLifetimeClosed *prometheus.Desc
+}
+
+func newPoolCollector() *poolCollector {
+	var desc = func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		open:              make(map[*sql.DB]struct{}),
+		pools:             desc("pools_open", "Connection pools open"),
+		openedTotal:       desc("pools_opened_total", "Connection pools opened"),
+		connections:       desc("open_connections", "Connections established, in use or idle"),
+		inUse:             desc("in_use_connections", "Connections in use"),
+		idle:              desc("idle_connections", "Idle connections"),
+		waitCount:         desc("wait_count_total", "Connections waited for"),
+		waitDuration:      desc("wait_duration_seconds_total", "Time blocked waiting for a connection"),
+		maxIdleClosed:     desc("max_idle_closed_total", "Connections closed by SetMaxIdleConns"),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "Connections closed by SetConnMaxIdleTime"),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Connections closed by SetConnMaxLifetime"),
+	}
+}
+
+func (p *poolCollector) track(db *sql.DB) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.open[db] = struct{}{}
+	p.opened++
+}
+
+func (p *poolCollector) untrack(db *sql.DB) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.open[db]; !ok {
+		return
+	}
+	delete(p.open, db)
+	p.closed = addCounters(p.closed, db.Stats())
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{p.pools, p.openedTotal, p.connections, p.inUse, p.idle,
+		p.waitCount, p.waitDuration, p.maxIdleClosed, p.maxIdleTimeClosed, p.maxLifetimeClosed} {
+		ch <- d
+	}
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	var (
+		sum    = p.closed
+		open   = len(p.open)
+		opened = p.opened
+	)
+	for db := range p.open {
+		s := db.Stats()
+		sum = addCounters(sum, s)
+		sum.OpenConnections += s.OpenConnections
+		sum.InUse += s.InUse
+		sum.Idle += s.Idle
+	}
+	p.mu.Unlock()
+
+	var (
+		gauge   = prometheus.GaugeValue
+		counter = prometheus.CounterValue
+	)
+	ch <- prometheus.MustNewConstMetric(p.pools, gauge, float64(open))
+	ch <- prometheus.MustNewConstMetric(p.openedTotal, counter, float64(opened))
+	ch <- prometheus.MustNewConstMetric(p.connections, gauge, float64(sum.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(p.inUse, gauge, float64(sum.InUse))
+	ch <- prometheus.MustNewConstMetric(p.idle, gauge, float64(sum.Idle))
+	ch <- prometheus.MustNewConstMetric(p.waitCount, counter, float64(sum.WaitCount))
+	ch <- prometheus.MustNewConstMetric(p.waitDuration, counter, sum.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(p.maxIdleClosed, counter, float64(sum.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(p.maxIdleTimeClosed, counter, float64(sum.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(p.maxLifetimeClosed, counter, float64(sum.MaxLifetimeClosed))
+}
+
+// addCounters of b to a, the gauges of a are kept
+func addCounters(a, b sql.DBStats) sql.DBStats {
+	a.WaitCount += b.WaitCount
+	a.WaitDuration += b.WaitDuration
+	a.MaxIdleClosed += b.MaxIdleClosed
+	a.MaxIdleTimeClosed += b.MaxIdleTimeClosed
+	a.MaxLifetimeClosed += b.MaxLifetimeClosed
+	return a
+}
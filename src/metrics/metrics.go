@@ -0,0 +1,94 @@
+// Package metrics are the Prometheus metrics of the server, served in
+// /metrics
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "restapi"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by method, route template and status",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of the statements by dialect, operation (prepare, exec or query) and type (select, insert...)",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"dialect", "op", "type"})
+
+	queryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Statements that failed by dialect, operation and type",
+	}, []string{"dialect", "op", "type"})
+)
+
+// Domain counters, whatever the API the resources are created with
+var (
+	EventsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_created_total",
+		Help:      "Events created",
+	})
+
+	ParticipantsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "participants_created_total",
+		Help:      "Participants created, the imported ones included",
+	})
+
+	TicketsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tickets_created_total",
+		Help:      "Participants registered for an event",
+	})
+)
+
+// ObserveRequest of a route template, e.g. /api/v1/event/:id
+func ObserveRequest(method, route string, status int, d time.Duration) {
+	var code = strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(d.Seconds())
+}
+
+// ObserveQuery of a statement of the dialect, see database.observe
+func ObserveQuery(dialect, op, statement string, d time.Duration, failed bool) {
+	var kind = statementType(statement)
+	queryDuration.WithLabelValues(dialect, op, kind).Observe(d.Seconds())
+	if failed {
+		queryErrors.WithLabelValues(dialect, op, kind).Inc()
+	}
+}
+
+// The first keyword of the statement, lowercase, other for the rest
+func statementType(statement string) string {
+	var fields = strings.Fields(statement)
+	if len(fields) == 0 {
+		return "other"
+	}
+
+	switch kind := strings.ToLower(fields[0]); kind {
+	case "select", "insert", "update", "delete", "with":
+		return kind
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/audit"
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+)
+
+// Loopback rejects the clients on other hosts, by the address of the
+// connection since X-Forwarded-For can be sent by anyone
+func Loopback() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+			if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+				return controllers.Forbidden("Only available from the host of the server, or in the admin listener, ADMIN_ADDRESS")
+			}
+			return next(c)
+		}
+	}
+}
+
+// Audit records every request of the route with its outcome, see
+// audit.Record. It goes before Authenticate so the rejected ones are
+// recorded too.
+func Audit(action string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			var e = audit.Entry{
+				Time:    time.Now().UTC(),
+				Action:  action,
+				Address: c.Request().RemoteAddr,
+				Target:  c.Request().URL.String(),
+				Status:  c.Response().Status,
+			}
+			if err != nil {
+				e.Status, e.Detail, _ = controllers.StatusOf(err)
+			}
+			if e.Status == 0 {
+				e.Status = http.StatusOK
+			}
+			if p := auth.PrincipalOf(c); p != nil {
+				e.Subject, e.Role = p.Subject, string(p.Role)
+			}
+
+			if rerr := audit.Record(e); rerr != nil {
+				slog.ErrorContext(c.Request().Context(), "The audit entry could not be written", "action", action, "error", rerr)
+			}
+			return err
+		}
+	}
+}
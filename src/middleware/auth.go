@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+)
+
+const challenge = `Bearer realm="restapi-technical-test"`
+
+// Authenticate requires the credentials of one of the authenticators, the
+// ones of auth.Default if none is given. The principal is stored in the
+// echo context, see auth.PrincipalOf, and in the context of the request
+// for the GraphQL resolvers.
+func Authenticate(authenticators ...auth.Authenticator) echo.MiddlewareFunc {
+	if len(authenticators) == 0 {
+		authenticators = auth.Default()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var req = c.Request()
+
+			p, err := auth.Authenticate(req.Context(), req.Header, authenticators...)
+			switch {
+			case errors.Is(err, auth.ErrNoCredentials):
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+				return controllers.Unauthorized("An API key in the " + auth.HeaderAPIKey + " header or a bearer token is required")
+			case errors.Is(err, auth.ErrInvalidCredentials):
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge+`, error="invalid_token"`)
+				return controllers.Unauthorized("The credentials are not valid")
+			case err != nil:
+				return controllers.Internal("The credentials could not be checked", err)
+			}
+
+			auth.WithPrincipal(c, p)
+			c.SetRequest(req.WithContext(auth.NewContext(req.Context(), p)))
+			return next(c)
+		}
+	}
+}
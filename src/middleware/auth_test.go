@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+)
+
+func TestAuthenticate(t *testing.T) {
+	var (
+		secret = []byte("s3cret")
+		jwt    = &auth.JWT{Secret: secret}
+	)
+
+	valid, err := auth.SignHS256(secret, "ana", "", auth.Staff, "", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := auth.SignHS256(secret, "ana", "", auth.Staff, "", "", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := auth.SignHS256([]byte("other"), "ana", "", auth.Staff, "", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		header    map[string]string
+		status    int
+		challenge string
+	}{
+		{name: "no credentials", status: http.StatusUnauthorized, challenge: `Bearer realm="restapi-technical-test"`},
+		{name: "basic", header: map[string]string{"Authorization": "Basic YW5hOnB3"}, status: http.StatusUnauthorized, challenge: `Bearer realm="restapi-technical-test"`},
+		{name: "API key without prefix", header: map[string]string{auth.HeaderAPIKey: "0123456789abcdef"}, status: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+		{name: "expired token", header: map[string]string{"Authorization": "Bearer " + expired}, status: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+		{name: "token of another key", header: map[string]string{"Authorization": "Bearer " + other}, status: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+		{name: "malformed token", header: map[string]string{"Authorization": "Bearer abc"}, status: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+		{name: "valid token", header: map[string]string{"Authorization": "Bearer " + valid}, status: http.StatusOK},
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+	e.GET("/", func(c echo.Context) error {
+		p := auth.PrincipalOf(c)
+		if p == nil || auth.FromContext(c.Request().Context()) != p {
+			t.Error("The principal is missing in the echo context or in the one of the request")
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		return c.String(http.StatusOK, p.Subject)
+	}, Authenticate(auth.APIKeys{}, jwt))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status == http.StatusOK {
+				if rec.Body.String() != "ana" {
+					t.Errorf("body = %q, want the subject of the token", rec.Body)
+				}
+				return
+			}
+
+			if got := rec.Header().Get(echo.HeaderWWWAuthenticate); !strings.Contains(got, tt.challenge) {
+				t.Errorf("WWW-Authenticate = %q, want %q in it", got, tt.challenge)
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(got, "application/problem+json") {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+
+			var p struct {
+				Status int    `json:"status"`
+				Detail string `json:"detail"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatalf("The body is not a problem: %v, %s", err, rec.Body)
+			}
+			if p.Status != tt.status || p.Detail == "" {
+				t.Errorf("problem = %+v, want the status %d and a detail", p, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+)
+
+// Scope finds what the request touches, nil if there's nothing to check
+type Scope func(c echo.Context) (repository.Check, error)
+
+// Allow requires the permission from the role of the principal, Authenticate
+// must run first. The scopes only apply to the roles limited to their own
+// events, the organizers, see repository.Authorize.
+func Allow(permission auth.Permission, scopes ...Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var p = auth.PrincipalOf(c)
+
+			if !p.Can(permission) {
+				return controllers.Forbidden("The role doesn't have the " + string(permission) + " permission")
+			}
+			if len(scopes) == 0 || !p.Role.Scoped() {
+				return next(c)
+			}
+
+			var checks = make([]repository.Check, 0, len(scopes))
+			for _, scope := range scopes {
+				check, err := scope(c)
+				if err != nil {
+					return err
+				}
+				if check != nil {
+					checks = append(checks, check)
+				}
+			}
+
+			db := persistence()
+			if err := db.Connect(c.Request().Context()); err != nil {
+				return controllers.Internal("Database connection failed", err)
+			}
+			defer func() {
+				if err := db.Close(); err != nil {
+					panic(err)
+				}
+			}()
+
+			ctx := c.Request().Context()
+
+			if err := repository.Authorize(ctx, db, permission, checks...); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// EventParam scopes the routes of an event, by the ID in the path parameter
+func EventParam(name string) Scope {
+	return func(c echo.Context) (repository.Check, error) {
+		id, err := controllers.IntParam(c, name)
+		if err != nil {
+			return nil, err
+		}
+		return repository.OwnEvent(int64(id)), nil
+	}
+}
+
+// TicketParam scopes the routes of a ticket to the organizers of its event
+func TicketParam(name string) Scope {
+	return func(c echo.Context) (repository.Check, error) {
+		id, err := controllers.IntParam(c, name)
+		if err != nil {
+			return nil, err
+		}
+		return repository.OwnTicket(int64(id)), nil
+	}
+}
+
+// ParticipantParam scopes the routes of a participant to the organizers of
+// every event it has a ticket for
+func ParticipantParam(name string) Scope {
+	return func(c echo.Context) (repository.Check, error) {
+		id, err := controllers.IntParam(c, name)
+		if err != nil {
+			return nil, err
+		}
+		return repository.OwnParticipant(int64(id)), nil
+	}
+}
+
+// TicketBody scopes the event of the ticket in the request body, e.g. the
+// one a ticket is created for or moved to. The bodies without an event are
+// left to the handler.
+func TicketBody() Scope {
+	return func(c echo.Context) (repository.Check, error) {
+		var req = c.Request()
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, controllers.BadRequest("The request body could not be read")
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		var ticket struct {
+			Event json.Number `json:"event"`
+		}
+		if json.Unmarshal(body, &ticket) != nil || ticket.Event == "" {
+			return nil, nil
+		}
+
+		event, err := strconv.ParseInt(ticket.Event.String(), 10, 64)
+		if err != nil {
+			return nil, nil
+		}
+		return repository.OwnEvent(event), nil
+	}
+}
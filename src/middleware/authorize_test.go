@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+)
+
+func TestAllow(t *testing.T) {
+	// ana organizes the event 1 and bob the event 2, the participant 100
+	// only has a ticket for the event 1, 300 one for each, 400 none
+	usePersistence(t, organizers{
+		events:       map[int64]string{1: "ana", 2: "bob"},
+		tickets:      map[int64]int64{10: 1, 20: 2},
+		participants: map[int64][]int64{100: {1}, 200: {2}, 300: {1, 2}, 400: {}},
+	})
+
+	var principals = map[string]*auth.Principal{
+		"ana":    {Subject: "ana", Role: auth.Organizer},
+		"viewer": {Subject: "vic", Role: auth.Viewer},
+		"staff":  {Subject: "sam", Role: auth.Staff},
+		"admin":  {Subject: "adm", Role: auth.Admin},
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return as(principals[c.Request().Header.Get("X-Test-Principal")])(next)(c)
+		}
+	})
+
+	// The handlers of the body scope must still read the body
+	var ok = func(c echo.Context) error {
+		body, _ := io.ReadAll(c.Request().Body)
+		return c.String(http.StatusOK, string(body))
+	}
+	e.PUT("/events/:id", ok, Allow(auth.ManageEvents, EventParam("id")))
+	e.POST("/events/:event-id/participant/:participant-id", ok, Allow(auth.RegisterTickets, EventParam("event-id")))
+	e.PUT("/tickets/:id", ok, Allow(auth.ManageTickets, TicketParam("id"), TicketBody()))
+	e.POST("/tickets", ok, Allow(auth.RegisterTickets, TicketBody()))
+	e.DELETE("/participants/:id", ok, Allow(auth.ManageParticipants, ParticipantParam("id")))
+	e.DELETE("/events/:id/participants", ok, Allow(auth.Administer))
+
+	tests := []struct {
+		name      string
+		principal string
+		method    string
+		path      string
+		body      string
+		status    int
+	}{
+		{name: "own event", principal: "ana", method: http.MethodPut, path: "/events/1", status: http.StatusOK},
+		{name: "event of another organizer", principal: "ana", method: http.MethodPut, path: "/events/2", status: http.StatusForbidden},
+		{name: "missing event, left to the handler", principal: "ana", method: http.MethodPut, path: "/events/3", status: http.StatusOK},
+		{name: "bad event ID", principal: "ana", method: http.MethodPut, path: "/events/x", status: http.StatusUnprocessableEntity},
+		{name: "register for own event", principal: "ana", method: http.MethodPost, path: "/events/1/participant/200", status: http.StatusOK},
+		{name: "register for another event", principal: "ana", method: http.MethodPost, path: "/events/2/participant/100", status: http.StatusForbidden},
+
+		{name: "own ticket", principal: "ana", method: http.MethodPut, path: "/tickets/10", body: `{"event": 1, "participant": 100}`, status: http.StatusOK},
+		{name: "ticket of another organizer", principal: "ana", method: http.MethodPut, path: "/tickets/20", body: `{"event": 1, "participant": 100}`, status: http.StatusForbidden},
+		{name: "own ticket moved to another event", principal: "ana", method: http.MethodPut, path: "/tickets/10", body: `{"event": 2, "participant": 100}`, status: http.StatusForbidden},
+		{name: "new ticket for own event", principal: "ana", method: http.MethodPost, path: "/tickets", body: `{"event": 1, "participant": 200}`, status: http.StatusOK},
+		{name: "new ticket for another event", principal: "ana", method: http.MethodPost, path: "/tickets", body: `{"event": 2, "participant": 100}`, status: http.StatusForbidden},
+		{name: "new ticket without event, left to the handler", principal: "ana", method: http.MethodPost, path: "/tickets", body: `{"participant": 100}`, status: http.StatusOK},
+
+		{name: "participant of own event", principal: "ana", method: http.MethodDelete, path: "/participants/100", status: http.StatusOK},
+		{name: "participant of another event", principal: "ana", method: http.MethodDelete, path: "/participants/200", status: http.StatusForbidden},
+		{name: "participant of both events", principal: "ana", method: http.MethodDelete, path: "/participants/300", status: http.StatusForbidden},
+		{name: "participant without tickets", principal: "ana", method: http.MethodDelete, path: "/participants/400", status: http.StatusForbidden},
+		{name: "organizer on an admin route", principal: "ana", method: http.MethodDelete, path: "/events/1/participants", status: http.StatusForbidden},
+
+		{name: "viewer updates an event", principal: "viewer", method: http.MethodPut, path: "/events/1", status: http.StatusForbidden},
+		{name: "viewer registers", principal: "viewer", method: http.MethodPost, path: "/tickets", body: `{"event": 1, "participant": 100}`, status: http.StatusForbidden},
+		{name: "viewer deletes a participant", principal: "viewer", method: http.MethodDelete, path: "/participants/100", status: http.StatusForbidden},
+		{name: "staff updates an event", principal: "staff", method: http.MethodPut, path: "/events/1", status: http.StatusForbidden},
+		{name: "staff moves a ticket", principal: "staff", method: http.MethodPut, path: "/tickets/10", body: `{"event": 1, "participant": 100}`, status: http.StatusForbidden},
+		{name: "staff deletes a participant", principal: "staff", method: http.MethodDelete, path: "/participants/100", status: http.StatusForbidden},
+		// Staff isn't scoped, it registers at the door of any event
+		{name: "staff registers for any event", principal: "staff", method: http.MethodPost, path: "/tickets", body: `{"event": 2, "participant": 100}`, status: http.StatusOK},
+		{name: "no principal", method: http.MethodPut, path: "/events/1", status: http.StatusForbidden},
+
+		{name: "admin updates any event", principal: "admin", method: http.MethodPut, path: "/events/2", status: http.StatusOK},
+		{name: "admin deletes any participant", principal: "admin", method: http.MethodDelete, path: "/participants/300", status: http.StatusOK},
+		{name: "admin on an admin route", principal: "admin", method: http.MethodDelete, path: "/events/1/participants", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("X-Test-Principal", tt.principal)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != tt.body {
+				t.Errorf("The handler read %q, want the body %q", rec.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestAllowFailedCheck(t *testing.T) {
+	usePersistence(t, organizers{fail: true})
+
+	e := echo.New()
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+	e.PUT("/events/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, as(&auth.Principal{Subject: "ana", Role: auth.Organizer}), Allow(auth.ManageEvents, EventParam("id")))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/events/1", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 when the organizers can't be checked", rec.Code)
+	}
+}
+
+// as authenticates the requests as Authenticate would, as the principal
+func as(p *auth.Principal) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.WithPrincipal(c, p)
+			c.SetRequest(c.Request().WithContext(auth.NewContext(c.Request().Context(), p)))
+			return next(c)
+		}
+	}
+}
+
+// usePersistence makes the connector the database of the middlewares
+// until the test ends
+func usePersistence(t *testing.T, connector driver.Connector) {
+	t.Helper()
+
+	db := sql.OpenDB(connector)
+	previous := persistence
+	persistence = func() database.Connecter { return openConn{db} }
+	t.Cleanup(func() {
+		persistence = previous
+		db.Close()
+	})
+}
+
+// openConn is a database.Connecter on a pool that stays open, the one
+// of the test
+type openConn struct{ *sql.DB }
+
+func (openConn) Connect(context.Context) error { return nil }
+func (openConn) Close() error                  { return nil }
+
+// organizers answers the queries of auth.OrganizesEvent, OrganizesTicket
+// and OrganizesParticipant as the tables would
+type organizers struct {
+	events       map[int64]string  // Organizer by event
+	tickets      map[int64]int64   // Event by ticket
+	participants map[int64][]int64 // Events of the tickets by participant
+	fail         bool
+}
+
+func (o organizers) Connect(context.Context) (driver.Conn, error) { return organizersConn{o}, nil }
+func (o organizers) Driver() driver.Driver                        { return nil }
+
+type organizersConn struct{ organizers }
+
+func (c organizersConn) Prepare(q string) (driver.Stmt, error) {
+	return organizersStmt{c.organizers, q}, nil
+}
+func (organizersConn) Close() error              { return nil }
+func (organizersConn) Begin() (driver.Tx, error) { return nil, errors.New("no transactions") }
+
+type organizersStmt struct {
+	organizers
+	q string
+}
+
+func (organizersStmt) Close() error  { return nil }
+func (organizersStmt) NumInput() int { return 2 }
+func (organizersStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("read only")
+}
+
+func (s organizersStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.fail {
+		return nil, errors.New("connection reset")
+	}
+
+	var (
+		id, _      = args[0].(int64)
+		subject, _ = args[1].(string)
+		organizes  = func(event int64) bool { return s.events[event] == subject }
+		ok         bool
+	)
+
+	switch {
+	case strings.Contains(s.q, "FROM events WHERE id"):
+		_, exists := s.events[id]
+		ok = !exists || organizes(id)
+	case strings.Contains(s.q, "FROM tickets WHERE id"):
+		event, exists := s.tickets[id]
+		ok = !exists || organizes(event)
+	case strings.Contains(s.q, "FROM participants AS p"):
+		events, exists := s.participants[id]
+		ok = !exists || len(events) > 0
+		for _, event := range events {
+			ok = ok && organizes(event)
+		}
+	default:
+		return nil, errors.New("unexpected statement: " + s.q)
+	}
+	return &organizesRows{ok: ok}, nil
+}
+
+type organizesRows struct {
+	ok   bool
+	done bool
+}
+
+func (*organizesRows) Columns() []string { return []string{"ok"} }
+func (*organizesRows) Close() error      { return nil }
+
+func (r *organizesRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.ok
+	return nil
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Deadline cancels the context of the request, and so its queries, after
+// d, see the deadline package. It goes first in the middleware of a route
+// so the checks of Allow and Idempotency are bounded too. The request is
+// answered with 504 if it fails after the deadline.
+func Deadline(d time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+
+			// Not restored, the error handler looks at the deadline
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKey        = 255 // idempotency_keys.idempotency_key
+)
+
+// Response headers stored and replayed along with the body
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, "ETag"}
+
+type storedResponse struct {
+	hash    string
+	status  int
+	headers sql.NullString
+	body    sql.NullString
+}
+
+// Idempotency makes POST requests sent with an Idempotency-Key header safe
+// to retry. The first request reserves the key, its response is stored for
+// constants.IdempotencyTTL and replayed to the retries. The keys are the
+// ones of each principal, it goes after Authenticate. The same key with a
+// different request is rejected with 422, server errors and panics are not
+// stored.
+func Idempotency() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var (
+				req = c.Request()
+				key = req.Header.Get(headerIdempotencyKey)
+			)
+
+			if req.Method != http.MethodPost || key == "" {
+				return next(c)
+			}
+
+			if len(key) > maxIdempotencyKey {
+				return controllers.Invalid("The Idempotency-Key header is too long", models.FieldError{
+					Field:   headerIdempotencyKey,
+					Message: fmt.Sprintf("must be at most %d characters", maxIdempotencyKey),
+				})
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return controllers.BadRequest("The request body could not be read")
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			db := persistence()
+			if err = db.Connect(c.Request().Context()); err != nil {
+				return controllers.Internal("Database connection failed", err)
+			}
+			defer func() {
+				if err = db.Close(); err != nil {
+					panic(err)
+				}
+			}()
+
+			var (
+				hash      = requestHash(req, body)
+				principal = principalOf(c)
+				ctx       = c.Request().Context()
+			)
+
+			reserved, err := reserve(ctx, db, principal, key, hash)
+			if err != nil {
+				return controllers.Internal("The Idempotency-Key could not be stored", err)
+			}
+
+			if !reserved {
+				stored, err := lookup(ctx, db, principal, key)
+				if err == sql.ErrNoRows {
+					return controllers.Conflict("The Idempotency-Key expired while it was being checked, try again")
+				}
+				if err != nil {
+					return controllers.Internal("The Idempotency-Key could not be retrieved", err)
+				}
+
+				switch {
+				case stored.hash != hash:
+					return controllers.Invalid("The Idempotency-Key was already used with a different request")
+				case stored.status == 0:
+					return controllers.Conflict("A request with the same Idempotency-Key is still being processed")
+				}
+				return replay(c, stored)
+			}
+
+			// Stored even if the client is gone, or the key would stay reserved
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), deadline.Check)
+			defer cancel()
+
+			rec := &recorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+
+			// A handler that panics leaves no response to store, the key is
+			// released for the retries before Recover answers
+			defer func() {
+				if r := recover(); r != nil {
+					c.Response().Writer = rec.ResponseWriter
+					if err := release(ctx, db, principal, key); err != nil {
+						slog.ErrorContext(req.Context(), "The Idempotency-Key could not be released", "error", err)
+					}
+					panic(r)
+				}
+			}()
+
+			if err = next(c); err != nil {
+				c.Error(err)
+			}
+			c.Response().Writer = rec.ResponseWriter
+
+			if status := c.Response().Status; status >= http.StatusInternalServerError {
+				err = release(ctx, db, principal, key)
+			} else {
+				err = save(ctx, db, principal, key, status, c.Response().Header(), rec.body.String())
+			}
+			if err != nil {
+				slog.ErrorContext(req.Context(), "The idempotent response could not be stored", "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+// A retry with another method, path, query or body is a different request
+func requestHash(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "?" + req.URL.RawQuery + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// principalOf the request the key belongs to, empty if it's anonymous
+func principalOf(c echo.Context) string {
+	if p := auth.PrincipalOf(c); p != nil {
+		return p.Subject
+	}
+	return ""
+}
+
+// reserve inserts the key without a response, false if it already exists.
+// Expired keys are purged first so they can be reused.
+func reserve(ctx context.Context, db database.Connecter, principal, key, hash string) (bool, error) {
+	var dq, iq string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		dq = "DELETE FROM idempotency_keys WHERE expires_at < $1;"
+		iq = "INSERT INTO idempotency_keys(principal, idempotency_key, request_hash, expires_at) VALUES($1, $2, $3, $4) ON CONFLICT (principal, idempotency_key) DO NOTHING;"
+	case storage.MySQL:
+		dq = "DELETE FROM idempotency_keys WHERE expires_at < ?;"
+		iq = "INSERT INTO idempotency_keys(principal, idempotency_key, request_hash, expires_at) VALUES(?, ?, ?, ?) ON DUPLICATE KEY UPDATE idempotency_key = idempotency_key;"
+	}
+
+	now := time.Now().UTC()
+
+	if _, err := controllers.Exec(ctx, db, dq, now); err != nil {
+		return false, err
+	}
+
+	n, err := controllers.Exec(ctx, db, iq, principal, key, hash, now.Add(constants.IdempotencyTTL))
+	return n == 1, err
+}
+
+func lookup(ctx context.Context, db database.Connecter, principal, key string) (storedResponse, error) {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "SELECT request_hash, status, headers, body FROM idempotency_keys WHERE principal = $1 AND idempotency_key = $2;"
+	case storage.MySQL:
+		q = "SELECT request_hash, status, headers, body FROM idempotency_keys WHERE principal = ? AND idempotency_key = ?;"
+	}
+
+	var stored storedResponse
+
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return stored, err
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, principal, key).Scan(&stored.hash, &stored.status, &stored.headers, &stored.body)
+	return stored, err
+}
+
+func save(ctx context.Context, db database.Connecter, principal, key string, status int, header http.Header, body string) error {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "UPDATE idempotency_keys SET status = $1, headers = $2, body = $3 WHERE principal = $4 AND idempotency_key = $5;"
+	case storage.MySQL:
+		q = "UPDATE idempotency_keys SET status = ?, headers = ?, body = ? WHERE principal = ? AND idempotency_key = ?;"
+	}
+
+	headers := make(map[string]string, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if v := header.Get(name); v != "" {
+			headers[name] = v
+		}
+	}
+
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+
+	_, err = controllers.Exec(ctx, db, q, status, string(encoded), body, principal, key)
+	return err
+}
+
+// release deletes the key so the request can be retried
+func release(ctx context.Context, db database.Connecter, principal, key string) error {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "DELETE FROM idempotency_keys WHERE principal = $1 AND idempotency_key = $2;"
+	case storage.MySQL:
+		q = "DELETE FROM idempotency_keys WHERE principal = ? AND idempotency_key = ?;"
+	}
+
+	_, err := controllers.Exec(ctx, db, q, principal, key)
+	return err
+}
+
+func replay(c echo.Context, stored storedResponse) error {
+	var headers map[string]string
+	if stored.headers.Valid {
+		if err := json.Unmarshal([]byte(stored.headers.String), &headers); err != nil {
+			return controllers.Internal("The stored response could not be decoded", err)
+		}
+	}
+
+	res := c.Response()
+	for name, v := range headers {
+		res.Header().Set(name, v)
+	}
+	res.Header().Set(headerIdempotentReplayed, "true")
+	res.WriteHeader(stored.status)
+
+	_, err := io.WriteString(res, stored.body.String)
+	return err
+}
+
+// recorder keeps a copy of everything written to the client
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
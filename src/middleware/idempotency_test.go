@@ -0,0 +1,378 @@
+package middleware
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+)
+
+func TestIdempotencyReplay(t *testing.T) {
+	var (
+		calls int32
+		e     = idempotent(t, func(c echo.Context) error {
+			n := atomic.AddInt32(&calls, 1)
+			c.Response().Header().Set("ETag", `"1"`)
+			c.Response().Header().Set(echo.HeaderLocation, "/api/v1/participant/1")
+			c.Response().Header().Set("X-Not-Stored", "yes")
+			return c.JSON(http.StatusCreated, map[string]int32{"id": n})
+		})
+	)
+
+	first := post(e, "ana", "k1", `{"firstname": "Ana"}`)
+	if first.Code != http.StatusCreated || first.Header().Get(headerIdempotentReplayed) != "" {
+		t.Fatalf("first request = %d %v, want 201 not replayed", first.Code, first.Header())
+	}
+
+	again := post(e, "ana", "k1", `{"firstname": "Ana"}`)
+	if calls != 1 {
+		t.Errorf("The handler ran %d times, want once", calls)
+	}
+	if again.Code != http.StatusCreated || again.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", again.Code, again.Body, first.Code, first.Body)
+	}
+	if again.Header().Get(headerIdempotentReplayed) != "true" {
+		t.Error("The replay has no Idempotent-Replayed header")
+	}
+	for _, name := range replayedHeaders {
+		if got, want := again.Header().Get(name), first.Header().Get(name); got != want {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+	if again.Header().Get("X-Not-Stored") != "" {
+		t.Error("A header outside replayedHeaders was replayed")
+	}
+
+	// The keys of each principal are their own
+	if other := post(e, "bob", "k1", `{"firstname": "Ana"}`); other.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("The key of another principal = %d after %d calls, want a new 201", other.Code, calls)
+	}
+}
+
+func TestIdempotencyDifferentRequest(t *testing.T) {
+	var calls int32
+	e := idempotent(t, func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return c.NoContent(http.StatusCreated)
+	})
+
+	post(e, "ana", "k1", `{"firstname": "Ana"}`)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "other body", path: "/participants", body: `{"firstname": "Bob"}`, status: http.StatusUnprocessableEntity},
+		{name: "other path", path: "/events", body: `{"firstname": "Ana"}`, status: http.StatusUnprocessableEntity},
+		{name: "other query", path: "/participants?dry=1", body: `{"firstname": "Ana"}`, status: http.StatusUnprocessableEntity},
+		{name: "same request", path: "/participants", body: `{"firstname": "Ana"}`, status: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := send(e, http.MethodPost, tt.path, "ana", "k1", tt.body)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("The handler ran %d times, want once", calls)
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		finish  = make(chan struct{})
+		e       = idempotent(t, func(c echo.Context) error {
+			close(started)
+			<-finish
+			return c.NoContent(http.StatusCreated)
+		})
+		done = make(chan *httptest.ResponseRecorder)
+	)
+
+	go func() { done <- post(e, "ana", "k1", `{}`) }()
+	<-started
+
+	if rec := post(e, "ana", "k1", `{}`); rec.Code != http.StatusConflict {
+		t.Errorf("status while in flight = %d, want 409: %s", rec.Code, rec.Body)
+	}
+
+	close(finish)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", rec.Code)
+	}
+	if rec := post(e, "ana", "k1", `{}`); rec.Code != http.StatusCreated || rec.Header().Get(headerIdempotentReplayed) != "true" {
+		t.Errorf("status once done = %d, want the replayed 201", rec.Code)
+	}
+}
+
+func TestIdempotencyReleasesTheKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(c echo.Context) error
+		status  int
+	}{
+		{
+			name: "server error",
+			handler: func(echo.Context) error {
+				return controllers.Internal("The database is gone", errors.New("connection reset"))
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:    "unavailable",
+			handler: func(c echo.Context) error { return c.NoContent(http.StatusServiceUnavailable) },
+			status:  http.StatusServiceUnavailable,
+		},
+		{
+			name:    "panic",
+			handler: func(echo.Context) error { panic("nil map") },
+			status:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				failed bool
+				e      = idempotent(t, func(c echo.Context) error {
+					if !failed {
+						failed = true
+						return tt.handler(c)
+					}
+					return c.NoContent(http.StatusCreated)
+				})
+			)
+
+			if rec := post(e, "ana", "k1", `{}`); rec.Code != tt.status {
+				t.Fatalf("first request = %d, want %d", rec.Code, tt.status)
+			}
+			if rec := post(e, "ana", "k1", `{}`); rec.Code != http.StatusCreated || rec.Header().Get(headerIdempotentReplayed) != "" {
+				t.Errorf("retry = %d, want a new 201", rec.Code)
+			}
+		})
+	}
+}
+
+func TestIdempotencyStoresClientErrors(t *testing.T) {
+	var calls int32
+	e := idempotent(t, func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return controllers.Conflict("The participant was already registered for the event previously")
+	})
+
+	post(e, "ana", "k1", `{}`)
+	if rec := post(e, "ana", "k1", `{}`); rec.Code != http.StatusConflict || rec.Header().Get(headerIdempotentReplayed) != "true" || calls != 1 {
+		t.Errorf("retry = %d after %d calls, want the replayed 409", rec.Code, calls)
+	}
+}
+
+func TestIdempotencyExpiredKey(t *testing.T) {
+	ttl := constants.IdempotencyTTL
+	constants.IdempotencyTTL = -time.Second
+	t.Cleanup(func() { constants.IdempotencyTTL = ttl })
+
+	var calls int32
+	e := idempotent(t, func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return c.NoContent(http.StatusCreated)
+	})
+
+	post(e, "ana", "k1", `{"firstname": "Ana"}`)
+	if rec := post(e, "ana", "k1", `{"firstname": "Bob"}`); rec.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("reused expired key = %d after %d calls, want a new 201", rec.Code, calls)
+	}
+}
+
+func TestIdempotencySkipped(t *testing.T) {
+	var calls int32
+	e := idempotent(t, func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		key    string
+		status int
+		calls  int32
+	}{
+		{name: "no key", method: http.MethodPost, status: http.StatusOK, calls: 2},
+		{name: "GET", method: http.MethodGet, key: "k1", status: http.StatusOK, calls: 2},
+		{name: "key too long", method: http.MethodPost, key: strings.Repeat("k", maxIdempotencyKey+1), status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			for i := 0; i < 2; i++ {
+				if rec := send(e, tt.method, "/participants", "ana", tt.key, `{}`); rec.Code != tt.status {
+					t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+				}
+			}
+			if calls != tt.calls {
+				t.Errorf("The handler ran %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+// idempotent serves the handler on every path behind Idempotency, with the
+// principal of the X-Test-Principal header and an empty idempotency_keys
+func idempotent(t *testing.T, handler echo.HandlerFunc) *echo.Echo {
+	t.Helper()
+
+	usePersistence(t, &idempotencyKeys{rows: make(map[[2]string]*idempotencyKey)})
+
+	e := echo.New()
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+	e.Use(Recover(), func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return as(&auth.Principal{Subject: c.Request().Header.Get("X-Test-Principal")})(next)(c)
+		}
+	}, Idempotency())
+	e.Any("/*", handler)
+	return e
+}
+
+func post(e *echo.Echo, principal, key, body string) *httptest.ResponseRecorder {
+	return send(e, http.MethodPost, "/participants", principal, key, body)
+}
+
+func send(e *echo.Echo, method, path, principal, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-Test-Principal", principal)
+	if key != "" {
+		req.Header.Set(headerIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// idempotencyKeys answers the statements of the middleware as the
+// idempotency_keys table would, by principal and key
+type idempotencyKeys struct {
+	sync.Mutex
+	rows map[[2]string]*idempotencyKey
+}
+
+type idempotencyKey struct {
+	hash          string
+	status        int64
+	headers, body interface{}
+	expires       time.Time
+}
+
+func (k *idempotencyKeys) Connect(context.Context) (driver.Conn, error) {
+	return idempotencyConn{k}, nil
+}
+func (k *idempotencyKeys) Driver() driver.Driver { return nil }
+
+type idempotencyConn struct{ keys *idempotencyKeys }
+
+func (c idempotencyConn) Prepare(q string) (driver.Stmt, error) {
+	return idempotencyStmt{c.keys, q}, nil
+}
+func (idempotencyConn) Close() error              { return nil }
+func (idempotencyConn) Begin() (driver.Tx, error) { return nil, errors.New("no transactions") }
+
+type idempotencyStmt struct {
+	keys *idempotencyKeys
+	q    string
+}
+
+func (idempotencyStmt) Close() error  { return nil }
+func (idempotencyStmt) NumInput() int { return -1 }
+
+func (s idempotencyStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.keys.Lock()
+	defer s.keys.Unlock()
+
+	var rows = s.keys.rows
+
+	switch {
+	case strings.HasPrefix(s.q, "DELETE FROM idempotency_keys WHERE expires_at"):
+		now := args[0].(time.Time)
+		for id, row := range rows {
+			if row.expires.Before(now) {
+				delete(rows, id)
+			}
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(s.q, "INSERT INTO idempotency_keys"):
+		id := [2]string{args[0].(string), args[1].(string)}
+		if _, ok := rows[id]; ok {
+			return driver.RowsAffected(0), nil
+		}
+		rows[id] = &idempotencyKey{hash: args[2].(string), expires: args[3].(time.Time)}
+		return driver.RowsAffected(1), nil
+
+	case strings.HasPrefix(s.q, "UPDATE idempotency_keys SET status"):
+		row, ok := rows[[2]string{args[3].(string), args[4].(string)}]
+		if !ok {
+			return driver.RowsAffected(0), nil
+		}
+		row.status, row.headers, row.body = args[0].(int64), args[1], args[2]
+		return driver.RowsAffected(1), nil
+
+	case strings.HasPrefix(s.q, "DELETE FROM idempotency_keys WHERE principal"):
+		delete(rows, [2]string{args[0].(string), args[1].(string)})
+		return driver.RowsAffected(1), nil
+	}
+	return nil, errors.New("unexpected statement: " + s.q)
+}
+
+func (s idempotencyStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(s.q, "SELECT request_hash, status, headers, body FROM idempotency_keys") {
+		return nil, errors.New("unexpected statement: " + s.q)
+	}
+
+	s.keys.Lock()
+	defer s.keys.Unlock()
+
+	var rows = &idempotencyRows{}
+	if row, ok := s.keys.rows[[2]string{args[0].(string), args[1].(string)}]; ok {
+		rows.row = []driver.Value{row.hash, row.status, row.headers, row.body}
+	}
+	return rows, nil
+}
+
+type idempotencyRows struct {
+	row []driver.Value // nil once read
+}
+
+func (*idempotencyRows) Columns() []string {
+	return []string{"request_hash", "status", "headers", "body"}
+}
+func (*idempotencyRows) Close() error { return nil }
+
+func (r *idempotencyRows) Next(dest []driver.Value) error {
+	if r.row == nil {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.row = nil
+	return nil
+}
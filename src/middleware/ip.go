@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+)
+
+// IPExtractor of the server, the remote address unless TRUSTED_PROXIES is
+// set, then X-Forwarded-For as long as the hops in it are those proxies.
+// The headers of the clients aren't trusted, they could pick the IP their
+// rate limits and logs are by.
+func IPExtractor() echo.IPExtractor {
+	if strings.TrimSpace(constants.TrustedProxies) == "" {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+
+	for _, cidr := range strings.Split(constants.TrustedProxies, ",") {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			slog.Warn("The trusted proxy is not a CIDR, it's ignored", "cidr", cidr)
+			continue
+		}
+		options = append(options, echo.TrustIPRange(network))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/logging"
+)
+
+// RequestID keeps the X-Request-ID of the client, if it's valid, or makes
+// a new one. It's sent back and stored in the context of the request, the
+// logs written with it and the error responses have it.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var (
+				req = c.Request()
+				id  = req.Header.Get(echo.HeaderXRequestID)
+			)
+			if !logging.ValidRequestID(id) {
+				id = logging.NewRequestID()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(req.WithContext(logging.WithRequestID(req.Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+// Logger writes a record per request, errors for the 5xx responses
+func Logger() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var start = time.Now()
+
+			// The error response is written here to log its status and size
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			var (
+				req    = c.Request()
+				status = c.Response().Status
+				level  = slog.LevelInfo
+			)
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			var attrs = []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("uri", req.RequestURI),
+				slog.String("route", c.Path()),
+				slog.Int("status", status),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("remote_ip", c.RealIP()),
+				slog.Int64("bytes_out", c.Response().Size),
+				slog.String("user_agent", req.UserAgent()),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			slog.LogAttrs(req.Context(), level, "request", attrs...)
+			return err
+		}
+	}
+}
+
+// Recover turns the panics of the handlers into 500, logged with their
+// stack
+func Recover() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				slog.ErrorContext(c.Request().Context(), "panic", "error", fmt.Sprint(r), "stack", string(debug.Stack()))
+				err = controllers.Internal("The request could not be completed", fmt.Errorf("panic: %v", r))
+			}()
+			return next(c)
+		}
+	}
+}
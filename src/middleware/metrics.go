@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+)
+
+// Metrics counts and times the requests by route template, it goes before
+// Logger, which writes the error responses
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var start = time.Now()
+
+			err := next(c)
+
+			metrics.ObserveRequest(c.Request().Method, routeOf(c), c.Response().Status, time.Since(start))
+			return err
+		}
+	}
+}
+
+// routeOf the request, its template, or unmatched if echo found no route,
+// the path is then the one of the request and it would make a series (or
+// a span name) for every URL
+func routeOf(c echo.Context) string {
+	var pc = reflect.ValueOf(c.Handler()).Pointer()
+	if c.Path() == "" || pc == reflect.ValueOf(echo.NotFoundHandler).Pointer() ||
+		pc == reflect.ValueOf(echo.MethodNotAllowedHandler).Pointer() {
+		return "unmatched"
+	}
+	return c.Path()
+}
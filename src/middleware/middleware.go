@@ -3,11 +3,28 @@ package middleware
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
 )
 
+// persistence is the database of the scopes of Allow and of the
+// idempotency keys, another one in the tests
+var persistence = func() database.Connecter {
+	return storage.Get(constants.Persistence)
+}
+
 func Apply(e *echo.Echo) {
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+	e.IPExtractor = IPExtractor()
+
+	e.Use(RequestID())
+	e.Use(Tracing())
+	e.Use(Metrics())
+	e.Use(Logger())
+	e.Use(Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
 	}))
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/controllers/graph"
+	"github.com/luisnquin/restapi-technical-test/src/ratelimit"
+)
+
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+	headerRetryAfter         = "Retry-After"
+)
+
+// RateLimit takes a token of the bucket of the client, of the policy the
+// request is charged to. The clients are their principals and the
+// anonymous ones their IP: before Authenticate it limits the IPs, so made
+// up credentials can't flood the checks, and after it the principals, so
+// they neither skip their limit nor add buckets. Clients are told their
+// limits in the RateLimit-* headers and get 429 with Retry-After once the
+// bucket is empty, if the store fails the request goes through.
+func RateLimit(store ratelimit.Store, policyOf Charge) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			policy := policyOf(c)
+			if policy.Off() {
+				return next(c)
+			}
+
+			r, err := store.Take(c.Request().Context(), policy.Name+":"+client(c), policy)
+			if err != nil {
+				slog.ErrorContext(c.Request().Context(), "The rate limit could not be checked", "error", err)
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set(headerRateLimitLimit, strconv.Itoa(policy.Limit))
+			h.Set(headerRateLimitRemaining, strconv.Itoa(r.Remaining))
+			h.Set(headerRateLimitReset, ceilSeconds(r.Reset))
+			h.Set(headerRateLimitPolicy, strconv.Itoa(policy.Limit)+";w="+ceilSeconds(policy.Period))
+
+			if !r.Allowed {
+				h.Set(headerRetryAfter, ceilSeconds(r.RetryAfter))
+				return controllers.TooManyRequests("The limit of " + policy.Name + " was reached, retry after the seconds of the Retry-After header")
+			}
+			return next(c)
+		}
+	}
+}
+
+// Charge is the policy a request is charged to
+type Charge func(c echo.Context) ratelimit.Policy
+
+// Always charges every request to the policy
+func Always(policy ratelimit.Policy) Charge {
+	return func(echo.Context) ratelimit.Policy { return policy }
+}
+
+// ByMethod charges GET and HEAD to reads and the rest to writes
+func ByMethod(reads, writes ratelimit.Policy) Charge {
+	return func(c echo.Context) ratelimit.Policy {
+		if m := c.Request().Method; m == http.MethodGet || m == http.MethodHead {
+			return reads
+		}
+		return writes
+	}
+}
+
+// ByOperation charges the GraphQL mutations to writes and the queries to
+// reads, every one of them is a POST. The body is read, up to
+// MaxGraphQLBodySize, and put back for the handler, the ones that can't be
+// read are writes and the handler gets their error.
+func ByOperation(reads, writes ratelimit.Policy) Charge {
+	return func(c echo.Context) ratelimit.Policy {
+		var req = c.Request()
+		if req.Body == nil {
+			return writes
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, constants.MaxGraphQLBodySize))
+		if err != nil {
+			req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), failingReader{err}))
+			return writes
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		var request struct {
+			Query         string `json:"query"`
+			OperationName string `json:"operationName"`
+		}
+		if json.Unmarshal(body, &request) != nil || graph.Mutates(request.Query, request.OperationName) {
+			return writes
+		}
+		return reads
+	}
+}
+
+// failingReader fails with the error the body was read with
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+// client of the request, its principal or else its IP, as told by the
+// IPExtractor of the server
+func client(c echo.Context) string {
+	if p := auth.PrincipalOf(c); p != nil {
+		return "sub:" + p.Subject
+	}
+	return "ip:" + c.RealIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,16 @@
+package models
+
+type (
+	// Health of the server, the readiness one has a check per dependency
+	Health struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks,omitempty"`
+	}
+
+	Version struct {
+		APIVersion  string `json:"api_version"`
+		Commit      string `json:"commit"`
+		BuildTime   string `json:"build_time"`
+		Persistence string `json:"persistence"`
+	}
+)
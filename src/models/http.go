@@ -7,6 +7,13 @@ type (
 		Method     string                 `json:"method"`
 		Params     map[string]interface{} `json:"params,omitempty"`
 		Data       interface{}            `json:"data,omitempty"`
+		Pagination *Pagination            `json:"pagination,omitempty"`
+	}
+
+	Pagination struct {
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+		Total  int `json:"total"`
 	}
 
 	Error struct {
@@ -20,6 +27,33 @@ type (
 		Context    string                 `json:"context"`
 		Method     string                 `json:"method"`
 		Params     map[string]interface{} `json:"params,omitempty"`
+		RequestID  string                 `json:"request_id,omitempty"`
 		Error      `json:"error"`
 	}
 )
+
+// RFC 7807 problem details, rendered as application/problem+json
+type (
+	Problem struct {
+		Type      string       `json:"type"`
+		Title     string       `json:"title"`
+		Status    int          `json:"status"`
+		Detail    string       `json:"detail,omitempty"`
+		Instance  string       `json:"instance,omitempty"`
+		Errors    []FieldError `json:"errors,omitempty"`
+		RequestID string       `json:"request_id,omitempty"` // X-Request-ID of the request
+	}
+
+	FieldError struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+)
+
+// Outcome of each item of a batch request sent with atomic=false
+type BatchResult struct {
+	Index  int         `json:"index"`
+	Status int         `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  *Problem    `json:"error,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+type (
+	// Summary of an import, rows are counted without the header
+	ImportReport struct {
+		DryRun     bool       `json:"dry_run"`
+		Rows       int        `json:"rows"`
+		Imported   int        `json:"imported"`
+		Registered int        `json:"registered"`
+		Failed     int        `json:"failed"`
+		Errors     []RowError `json:"errors"`
+	}
+
+	// Row is the line of the file, the header being the first one
+	RowError struct {
+		Row    int          `json:"row"`
+		Detail string       `json:"detail"`
+		Errors []FieldError `json:"errors,omitempty"`
+	}
+
+	ImportProgress struct {
+		Rows     int `json:"rows"`
+		Imported int `json:"imported"`
+		Failed   int `json:"failed"`
+	}
+)
@@ -4,9 +4,12 @@ import "time"
 
 type (
 	Event struct {
-		Id         uint16    `json:"id" sql:"id,pk"`
-		Name       string    `json:"name" sql:"name"`
-		Created_at time.Time `json:"created_at" sql:"created_at"`
+		Id         uint16     `json:"id" sql:"id,pk"`
+		Name       string     `json:"name" sql:"name"`
+		Created_at time.Time  `json:"created_at" sql:"created_at"`
+		Starts_at  *time.Time `json:"starts_at" sql:"starts_at"`
+		Ends_at    *time.Time `json:"ends_at" sql:"ends_at"`
+		Version    uint32     `json:"version" sql:"version"`
 	}
 	Events []Event
 )
@@ -17,6 +20,7 @@ type (
 		Firstname string `json:"firstname" sql:"firstname"`
 		Lastname  string `json:"lastname" sql:"lastname"`
 		Age       uint8  `json:"age" sql:"age"`
+		Version   uint32 `json:"version" sql:"version"`
 	}
 	Participants []Participant
 )
@@ -26,6 +30,7 @@ type (
 		Id          uint32 `json:"id" sql:"id,pk"`
 		Participant uint64 `json:"participant" sql:"participant"`
 		Event       uint16 `json:"event" sql:"event"`
+		Version     uint32 `json:"version" sql:"version"`
 	}
 	Tickets []Ticket
 
@@ -33,6 +38,7 @@ type (
 		Id          uint32 `json:"id" sql:"id,pk"`
 		Participant string `json:"participant" sql:"participant"`
 		Event       string `json:"event" sql:"event"`
+		Version     uint32 `json:"version" sql:"version"`
 	}
 	TicketViews []TicketView
 )
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UTC is the time in UTC, nil if it's nil. The columns of the times are
+// TIMESTAMP, without a time zone, the offset of a time is dropped when it's
+// written: 10:00-05:00 would be read back as 10:00Z instead of 15:00Z.
+func UTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUTC(t *testing.T) {
+	lima := time.FixedZone("-05:00", -5*60*60)
+
+	tests := []struct {
+		name string
+		in   *time.Time
+		want *time.Time
+	}{
+		{name: "nil", in: nil, want: nil},
+		{name: "utc", in: ptr(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)), want: ptr(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))},
+		{name: "offset", in: ptr(time.Date(2026, 3, 1, 10, 0, 0, 0, lima)), want: ptr(time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC))},
+		{name: "day after", in: ptr(time.Date(2026, 3, 1, 22, 30, 0, 0, lima)), want: ptr(time.Date(2026, 3, 2, 3, 30, 0, 0, time.UTC))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UTC(tt.in)
+
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("UTC(nil) = %v, want nil", got)
+				}
+				return
+			}
+
+			if got.Location() != time.UTC {
+				t.Errorf("UTC(%v) is in %v, want UTC", tt.in, got.Location())
+			}
+			// The wall clock is what a TIMESTAMP column keeps
+			if got.Format("2006-01-02 15:04:05") != tt.want.Format("2006-01-02 15:04:05") {
+				t.Errorf("UTC(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+			if !got.Equal(*tt.in) {
+				t.Errorf("UTC(%v) = %v, not the same instant", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestUTCKeepsTheInput(t *testing.T) {
+	in := time.Date(2026, 3, 1, 10, 0, 0, 0, time.FixedZone("+09:00", 9*60*60))
+
+	UTC(&in)
+
+	if _, offset := in.Zone(); offset != 9*60*60 {
+		t.Errorf("UTC changed the offset of its input to %d", offset)
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
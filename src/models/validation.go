@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Same constraints as the database schemas
+const (
+	MaxEventName       = 50
+	MaxParticipantName = 40
+	MinParticipantAge  = 18
+	MaxParticipantAge  = 129
+)
+
+func (e Event) Validate() []FieldError {
+	var errs []FieldError
+
+	switch n := utf8.RuneCountInString(e.Name); {
+	case n == 0:
+		errs = append(errs, FieldError{Field: "name", Message: "is required"})
+	case n > MaxEventName:
+		errs = append(errs, FieldError{Field: "name", Message: fmt.Sprintf("must be at most %d characters", MaxEventName)})
+	}
+
+	switch {
+	case e.Ends_at != nil && e.Starts_at == nil:
+		errs = append(errs, FieldError{Field: "starts_at", Message: "is required when ends_at is set"})
+	case e.Ends_at != nil && !e.Ends_at.After(*e.Starts_at):
+		errs = append(errs, FieldError{Field: "ends_at", Message: "must be after starts_at"})
+	}
+
+	return errs
+}
+
+func (p Participant) Validate() []FieldError {
+	var errs []FieldError
+
+	switch n := utf8.RuneCountInString(p.Firstname); {
+	case n == 0:
+		errs = append(errs, FieldError{Field: "firstname", Message: "is required"})
+	case n > MaxParticipantName:
+		errs = append(errs, FieldError{Field: "firstname", Message: fmt.Sprintf("must be at most %d characters", MaxParticipantName)})
+	}
+
+	if utf8.RuneCountInString(p.Lastname) > MaxParticipantName {
+		errs = append(errs, FieldError{Field: "lastname", Message: fmt.Sprintf("must be at most %d characters", MaxParticipantName)})
+	}
+
+	if p.Age < MinParticipantAge || p.Age > MaxParticipantAge {
+		errs = append(errs, FieldError{Field: "age", Message: fmt.Sprintf("must be between %d and %d", MinParticipantAge, MaxParticipantAge)})
+	}
+
+	return errs
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Memory store, the buckets of a single instance of the server
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+// How often the full buckets are dropped
+const sweepEvery = time.Minute
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket)}
+}
+
+func (m *Memory) Take(_ context.Context, key string, p Policy) (Result, error) {
+	if p.Off() {
+		return Result{Allowed: true, Remaining: p.Limit}, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var now = time.Now()
+	m.sweep(now)
+
+	var (
+		limit = float64(p.Limit)
+		rate  = limit / p.Period.Seconds() // Tokens per second
+	)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit, last: now}
+		m.buckets[key] = b
+	}
+	b.period = p.Period
+
+	b.tokens = math.Min(limit, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	var r Result
+	if b.tokens >= 1 {
+		b.tokens--
+		r.Allowed = true
+	} else {
+		r.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	r.Remaining = int(b.tokens)
+	r.Reset = seconds((limit - b.tokens) / rate)
+	return r, nil
+}
+
+// sweep drops the buckets that are full by now, they're the same as new ones
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.swept) < sweepEvery {
+		return
+	}
+	m.swept = now
+
+	for key, b := range m.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy of a token bucket, Limit requests at once and Limit more every
+// Period, e.g. 60 per minute is one more token every second
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// Off is a policy without limit
+func (p Policy) Off() bool {
+	return p.Limit <= 0 || p.Period <= 0
+}
+
+// The limits of each group of routes, "<requests>/<period>" in the
+// environment, e.g. RATE_LIMIT_READS=300/1m, or "off"
+var (
+	Reads       = FromEnv("RATE_LIMIT_READS", Policy{Name: "reads", Limit: 300, Period: time.Minute})
+	Writes      = FromEnv("RATE_LIMIT_WRITES", Policy{Name: "writes", Limit: 60, Period: time.Minute})
+	Persistence = FromEnv("RATE_LIMIT_PERSISTENCE", Policy{Name: "persistence", Limit: 5, Period: time.Hour})
+	// Every request of an IP, before its credentials are checked
+	IPs = FromEnv("RATE_LIMIT_IPS", Policy{Name: "ips", Limit: 600, Period: time.Minute})
+)
+
+// Default store of the server, the HTTP routes and the gRPC calls take
+// their tokens from the same buckets
+var Default Store = NewMemory()
+
+// FromEnv reads the limit of the policy from the variable, the default is
+// kept if it's unset or not valid
+func FromEnv(name string, def Policy) Policy {
+	var v = os.Getenv(name)
+	if v == "off" {
+		return Policy{Name: def.Name}
+	}
+
+	i := strings.IndexByte(v, '/')
+	if i < 0 {
+		return def
+	}
+
+	limit, err := strconv.Atoi(v[:i])
+	if err != nil || limit < 0 {
+		return def
+	}
+	period, err := time.ParseDuration(v[i+1:])
+	if err != nil || period <= 0 {
+		return def
+	}
+	return Policy{Name: def.Name, Limit: limit, Period: period}
+}
+
+// Result of taking a token
+type Result struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left
+	Reset      time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next token, if not allowed
+}
+
+// Store keeps the buckets, in memory by default, see NewMemory. Another
+// one, e.g. Redis, shares the limits between instances of the server.
+type Store interface {
+	Take(ctx context.Context, key string, p Policy) (Result, error)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+)
+
+// Check tells if the organizer of the subject may touch a resource, see
+// middleware.Allow for the REST routes
+type Check func(ctx context.Context, db database.Connecter, subject string) (bool, error)
+
+// Authorize requires the permission from the principal of the context,
+// the checks only apply to the organizers and all of them must pass
+func Authorize(ctx context.Context, db database.Connecter, permission auth.Permission, checks ...Check) error {
+	var p = auth.FromContext(ctx)
+
+	if !p.Can(permission) {
+		return controllers.Forbidden("The role doesn't have the " + string(permission) + " permission")
+	}
+	if !p.Role.Scoped() {
+		return nil
+	}
+
+	for _, check := range checks {
+		ok, err := check(ctx, db, p.Subject)
+		if err != nil {
+			return controllers.Internal("The organizers of the event could not be checked", err)
+		}
+		if !ok {
+			return controllers.Forbidden("Only the organizers of the event can do this")
+		}
+	}
+	return nil
+}
+
+func OwnEvent(id int64) Check {
+	return func(ctx context.Context, db database.Connecter, subject string) (bool, error) {
+		return auth.OrganizesEvent(ctx, db, subject, id)
+	}
+}
+
+func OwnTicket(id int64) Check {
+	return func(ctx context.Context, db database.Connecter, subject string) (bool, error) {
+		return auth.OrganizesTicket(ctx, db, subject, id)
+	}
+}
+
+func OwnParticipant(id int64) Check {
+	return func(ctx context.Context, db database.Connecter, subject string) (bool, error) {
+		return auth.OrganizesParticipant(ctx, db, subject, id)
+	}
+}
+
+// AssignCreator makes the organizer of the context one of the organizers
+// of the event it just created, in the transaction of the event
+func AssignCreator(ctx context.Context, db database.Preparer, event int64) error {
+	var p = auth.FromContext(ctx)
+	if p == nil || !p.Role.Scoped() {
+		return nil
+	}
+	if err := auth.AssignEvent(ctx, db, event, p.Subject); err != nil {
+		return controllers.Internal("The organizer of the new event could not be stored", err)
+	}
+	return nil
+}
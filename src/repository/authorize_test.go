@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+)
+
+func TestAuthorize(t *testing.T) {
+	var (
+		organizer = &auth.Principal{Subject: "ana", Role: auth.Organizer}
+		own       = func(id int64) Check {
+			return func(_ context.Context, _ database.Connecter, subject string) (bool, error) {
+				return subject == "ana" && id == 1, nil
+			}
+		}
+		failed Check = func(context.Context, database.Connecter, string) (bool, error) {
+			return false, errors.New("connection reset")
+		}
+	)
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		permission auth.Permission
+		checks     []Check
+		status     int // 0 if allowed
+	}{
+		{name: "no principal", permission: auth.Read, status: http.StatusForbidden},
+		{name: "viewer reads", principal: &auth.Principal{Role: auth.Viewer}, permission: auth.Read},
+		{name: "viewer manages an event", principal: &auth.Principal{Role: auth.Viewer}, permission: auth.ManageEvents, status: http.StatusForbidden},
+		{name: "staff registers", principal: &auth.Principal{Role: auth.Staff}, permission: auth.RegisterTickets, checks: []Check{own(2)}},
+		{name: "staff manages a ticket", principal: &auth.Principal{Role: auth.Staff}, permission: auth.ManageTickets, status: http.StatusForbidden},
+		{name: "staff creates a participant", principal: &auth.Principal{Role: auth.Staff}, permission: auth.CreateParticipants, status: http.StatusForbidden},
+		{name: "organizer, own event", principal: organizer, permission: auth.ManageEvents, checks: []Check{own(1)}},
+		{name: "organizer, another event", principal: organizer, permission: auth.ManageEvents, checks: []Check{own(2)}, status: http.StatusForbidden},
+		{name: "organizer, own ticket moved to another event", principal: organizer, permission: auth.ManageTickets, checks: []Check{own(1), own(2)}, status: http.StatusForbidden},
+		{name: "organizer, failed check", principal: organizer, permission: auth.ManageEvents, checks: []Check{failed}, status: http.StatusInternalServerError},
+		{name: "organizer administers", principal: organizer, permission: auth.Administer, status: http.StatusForbidden},
+		{name: "admin, another event", principal: &auth.Principal{Role: auth.Admin}, permission: auth.ManageEvents, checks: []Check{own(2), failed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.NewContext(ctx, tt.principal)
+			}
+
+			err := Authorize(ctx, nil, tt.permission, tt.checks...)
+			if got := statusOf(err); got != tt.status {
+				t.Errorf("Authorize() = %v, want the status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func statusOf(err error) int {
+	var (
+		forbidden *controllers.ForbiddenError
+		internal  *controllers.InternalError
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &forbidden):
+		return http.StatusForbidden
+	case errors.As(err, &internal):
+		return http.StatusInternalServerError
+	}
+	return -1
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// EventFilter of ListEvents, the events match all the members that aren't nil
+type EventFilter struct {
+	Name         *string // Part of the name, case insensitive
+	Scheduled    *bool   // Whether starts_at is set
+	StartsAfter  *time.Time
+	StartsBefore *time.Time
+}
+
+func (f EventFilter) where() where {
+	var w where
+	if f.Name != nil {
+		w.add("LOWER(name) LIKE ?", contains(*f.Name))
+	}
+	if f.Scheduled != nil && *f.Scheduled {
+		w.add("starts_at IS NOT NULL")
+	}
+	if f.Scheduled != nil && !*f.Scheduled {
+		w.add("starts_at IS NULL")
+	}
+	if f.StartsAfter != nil {
+		w.add("starts_at > ?", *f.StartsAfter)
+	}
+	if f.StartsBefore != nil {
+		w.add("starts_at < ?", *f.StartsBefore)
+	}
+	return w
+}
+
+// ListEvents brings a page of the events, the total of the page is set
+func ListEvents(ctx context.Context, db database.Preparer, f EventFilter, page *models.Pagination, desc bool) ([]models.Event, error) {
+	var events = make([]models.Event, 0)
+
+	err := list(ctx, db, "events", eventColumns, f.where(), page, desc, func(s scanner) error {
+		e, err := scanEvent(s)
+		events = append(events, e)
+		return err
+	})
+	return events, err
+}
+
+// EventsByID brings the events with the given IDs, the missing ones are
+// left out
+func EventsByID(ctx context.Context, db database.Preparer, ids []int64) ([]models.Event, error) {
+	var events = make([]models.Event, 0, len(ids))
+
+	err := byID(ctx, db, "events", eventColumns, ids, func(s scanner) error {
+		e, err := scanEvent(s)
+		events = append(events, e)
+		return err
+	})
+	return events, err
+}
+
+func GetEvent(ctx context.Context, db database.Preparer, id int64) (models.Event, error) {
+	events, err := EventsByID(ctx, db, []int64{id})
+	if err != nil {
+		return models.Event{}, err
+	}
+	if len(events) == 0 {
+		return models.Event{}, controllers.NotFound("Event not found")
+	}
+	return events[0], nil
+}
+
+// CreateEvent inserts the event, an organizer becomes one of its organizers
+// in the same transaction, an event nobody could manage isn't left behind
+func CreateEvent(ctx context.Context, db database.Connecter, request models.Event) (models.Event, error) {
+	if fields := request.Validate(); len(fields) > 0 {
+		return models.Event{}, controllers.Invalid("The event is not valid", fields...)
+	}
+
+	var (
+		event models.Event
+		q     string
+	)
+
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "INSERT INTO events(name, starts_at, ends_at) VALUES($1, $2, $3) RETURNING " + eventColumns + ";"
+	case storage.MySQL:
+		q = "INSERT INTO events(name, starts_at, ends_at) VALUES(?, ?, ?);"
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return event, controllers.Internal("The transaction could not be started", err)
+	}
+	defer tx.Rollback()
+
+	id, err := insert(ctx, tx, q, func(s scanner) error {
+		var err error
+		event, err = scanEvent(s)
+		return err
+	}, request.Name, models.UTC(request.Starts_at), models.UTC(request.Ends_at))
+	if err != nil {
+		return event, controllers.Rejected(ctx, err, controllers.BadRequest("The event was rejected, not valid"))
+	}
+
+	if constants.Persistence == storage.PostgreSQL {
+		id = int64(event.Id)
+	}
+	if err = AssignCreator(ctx, tx, id); err != nil {
+		return models.Event{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Event{}, controllers.Internal("The transaction could not be committed", err)
+	}
+	metrics.EventsCreated.Inc()
+
+	if constants.Persistence == storage.MySQL {
+		return GetEvent(ctx, db, id)
+	}
+	return event, nil
+}
+
+// UpdateEvent replaces the event if it's at the expected version, or at
+// any one if nil
+func UpdateEvent(ctx context.Context, db database.Preparer, id int64, request models.Event, expected *uint32) (models.Event, error) {
+	if fields := request.Validate(); len(fields) > 0 {
+		return models.Event{}, controllers.Invalid("The event is not valid", fields...)
+	}
+
+	version, err := checkVersion(ctx, db, "events", id, expected, "Event not found")
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	if err = updateEvent(ctx, db, id, request, version); err != nil {
+		return models.Event{}, err
+	}
+	return GetEvent(ctx, db, id)
+}
+
+// PatchEvent locks the event, gives it to patch, which checks its version
+// and returns the patched one, and writes it in the same transaction
+func PatchEvent(ctx context.Context, db database.Connecter, id int64, patch func(current models.Event) (models.Event, error)) (models.Event, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Event{}, controllers.Internal("The transaction could not be started", err)
+	}
+	defer tx.Rollback()
+
+	var current models.Event
+	err = lock(ctx, tx, "events", eventColumns, id, "Event not found", func(s scanner) (err error) {
+		current, err = scanEvent(s)
+		return err
+	})
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	patched, err := patch(current)
+	if err != nil {
+		return models.Event{}, err
+	}
+	if fields := patched.Validate(); len(fields) > 0 {
+		return models.Event{}, controllers.Invalid("The patched event is not valid", fields...)
+	}
+
+	if err = updateEvent(ctx, tx, id, patched, current.Version); err != nil {
+		return models.Event{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return models.Event{}, controllers.Internal("The transaction could not be committed", err)
+	}
+
+	patched.Id, patched.Created_at, patched.Version = current.Id, current.Created_at, current.Version+1
+	return patched, nil
+}
+
+// updateEvent writes the event if it's still at the version
+func updateEvent(ctx context.Context, db database.Preparer, id int64, e models.Event, version uint32) error {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "UPDATE events SET name = $1, starts_at = $2, ends_at = $3, version = version + 1 WHERE id = $4 AND version = $5;"
+	case storage.MySQL:
+		q = "UPDATE events SET name = ?, starts_at = ?, ends_at = ?, version = version + 1 WHERE id = ? AND version = ?;"
+	}
+
+	n, err := controllers.Exec(ctx, db, q, e.Name, models.UTC(e.Starts_at), models.UTC(e.Ends_at), id, version)
+	if err != nil {
+		return controllers.Rejected(ctx, err, controllers.BadRequest("The event was rejected, not valid"))
+	}
+	if n == 0 {
+		return controllers.PreconditionFailed("The event was modified by another request")
+	}
+	return nil
+}
+
+// DeleteEvent deletes the event and its tickets, the participants are kept
+func DeleteEvent(ctx context.Context, db database.Preparer, id int64, expected *uint32) error {
+	return remove(ctx, db, "events", id, expected, "Event not found", "The event was modified by another request")
+}
+
+// DeleteEventWithParticipants deletes the event, its tickets and the
+// participants that had one. The event is locked and check is given its
+// version before any participant is deleted, an error leaves every row as
+// it was.
+func DeleteEventWithParticipants(ctx context.Context, db database.Connecter, id int64, check func(version uint32) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return controllers.Internal("The transaction could not be started", err)
+	}
+	defer tx.Rollback()
+
+	var version uint32
+	err = lock(ctx, tx, "events", "version", id, "Event not found", func(s scanner) error {
+		return s.Scan(&version)
+	})
+	if err != nil {
+		return err
+	}
+	if err = check(version); err != nil {
+		return err
+	}
+
+	var pq, eq string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		pq = "DELETE FROM participants WHERE id IN (SELECT participant FROM tickets WHERE event = $1);"
+		eq = "DELETE FROM events WHERE id = $1 AND version = $2;"
+	case storage.MySQL:
+		pq = "DELETE FROM participants WHERE id IN (SELECT participant FROM tickets WHERE event = ?);"
+		eq = "DELETE FROM events WHERE id = ? AND version = ?;"
+	}
+
+	if _, err = controllers.Exec(ctx, tx, pq, id); err != nil {
+		return controllers.Internal("The participants could not be deleted", err)
+	}
+
+	n, err := controllers.Exec(ctx, tx, eq, id, version)
+	if err != nil {
+		return controllers.Internal("The event could not be deleted", err)
+	}
+	if n == 0 {
+		return controllers.PreconditionFailed("The event was modified by another request")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return controllers.Internal("The transaction could not be committed", err)
+	}
+	return nil
+}
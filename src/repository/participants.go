@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// ParticipantFilter of ListParticipants, the participants match all the
+// members that aren't nil
+type ParticipantFilter struct {
+	Name   *string // Part of the first name or of the last name, case insensitive
+	MinAge *int
+	MaxAge *int
+}
+
+func (f ParticipantFilter) where() where {
+	var w where
+	if f.Name != nil {
+		w.add("(LOWER(firstname) LIKE ? OR LOWER(lastname) LIKE ?)", contains(*f.Name), contains(*f.Name))
+	}
+	if f.MinAge != nil {
+		w.add("age >= ?", *f.MinAge)
+	}
+	if f.MaxAge != nil {
+		w.add("age <= ?", *f.MaxAge)
+	}
+	return w
+}
+
+// ListParticipants brings a page of the participants, the total of the
+// page is set
+func ListParticipants(ctx context.Context, db database.Preparer, f ParticipantFilter, page *models.Pagination, desc bool) ([]models.Participant, error) {
+	var participants = make([]models.Participant, 0)
+
+	err := list(ctx, db, "participants", participantColumns, f.where(), page, desc, func(s scanner) error {
+		p, err := scanParticipant(s)
+		participants = append(participants, p)
+		return err
+	})
+	return participants, err
+}
+
+// ParticipantsByID brings the participants with the given IDs, the
+// missing ones are left out
+func ParticipantsByID(ctx context.Context, db database.Preparer, ids []int64) ([]models.Participant, error) {
+	var participants = make([]models.Participant, 0, len(ids))
+
+	err := byID(ctx, db, "participants", participantColumns, ids, func(s scanner) error {
+		p, err := scanParticipant(s)
+		participants = append(participants, p)
+		return err
+	})
+	return participants, err
+}
+
+func GetParticipant(ctx context.Context, db database.Preparer, id int64) (models.Participant, error) {
+	participants, err := ParticipantsByID(ctx, db, []int64{id})
+	if err != nil {
+		return models.Participant{}, err
+	}
+	if len(participants) == 0 {
+		return models.Participant{}, controllers.NotFound("Participant not found")
+	}
+	return participants[0], nil
+}
+
+func CreateParticipant(ctx context.Context, db database.Preparer, request models.Participant) (models.Participant, error) {
+	if fields := request.Validate(); len(fields) > 0 {
+		return models.Participant{}, controllers.Invalid("The participant is not valid", fields...)
+	}
+
+	var (
+		participant = request
+		q           string
+	)
+
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "INSERT INTO participants(firstname, lastname, age) VALUES($1, $2, $3) RETURNING id, version;"
+	case storage.MySQL:
+		q = "INSERT INTO participants(firstname, lastname, age) VALUES(?, ?, ?);"
+	}
+
+	id, err := insert(ctx, db, q, func(s scanner) error {
+		return s.Scan(&participant.Id, &participant.Version)
+	}, request.Firstname, request.Lastname, request.Age)
+	if err != nil {
+		return models.Participant{}, controllers.Rejected(ctx, err, controllers.BadRequest("The participant was rejected, not valid"))
+	}
+
+	if constants.Persistence == storage.MySQL {
+		participant.Id, participant.Version = uint64(id), 1 // Default version
+	}
+	metrics.ParticipantsCreated.Inc()
+	return participant, nil
+}
+
+// UpdateParticipant replaces the participant if it's at the expected
+// version, or at any one if nil
+func UpdateParticipant(ctx context.Context, db database.Preparer, id int64, request models.Participant, expected *uint32) (models.Participant, error) {
+	if fields := request.Validate(); len(fields) > 0 {
+		return models.Participant{}, controllers.Invalid("The participant is not valid", fields...)
+	}
+
+	version, err := checkVersion(ctx, db, "participants", id, expected, "Participant not found")
+	if err != nil {
+		return models.Participant{}, err
+	}
+
+	if err = updateParticipant(ctx, db, id, request, version); err != nil {
+		return models.Participant{}, err
+	}
+
+	var participant = request
+	participant.Id, participant.Version = uint64(id), version+1
+	return participant, nil
+}
+
+// PatchParticipant locks the participant, gives it to patch, which checks
+// its version and returns the patched one, and writes it in the same
+// transaction
+func PatchParticipant(ctx context.Context, db database.Connecter, id int64, patch func(current models.Participant) (models.Participant, error)) (models.Participant, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Participant{}, controllers.Internal("The transaction could not be started", err)
+	}
+	defer tx.Rollback()
+
+	var current models.Participant
+	err = lock(ctx, tx, "participants", participantColumns, id, "Participant not found", func(s scanner) (err error) {
+		current, err = scanParticipant(s)
+		return err
+	})
+	if err != nil {
+		return models.Participant{}, err
+	}
+
+	patched, err := patch(current)
+	if err != nil {
+		return models.Participant{}, err
+	}
+	if fields := patched.Validate(); len(fields) > 0 {
+		return models.Participant{}, controllers.Invalid("The patched participant is not valid", fields...)
+	}
+
+	if err = updateParticipant(ctx, tx, id, patched, current.Version); err != nil {
+		return models.Participant{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return models.Participant{}, controllers.Internal("The transaction could not be committed", err)
+	}
+
+	patched.Id, patched.Version = current.Id, current.Version+1
+	return patched, nil
+}
+
+// updateParticipant writes the participant if it's still at the version
+func updateParticipant(ctx context.Context, db database.Preparer, id int64, p models.Participant, version uint32) error {
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "UPDATE participants SET firstname = $1, lastname = $2, age = $3, version = version + 1 WHERE id = $4 AND version = $5;"
+	case storage.MySQL:
+		q = "UPDATE participants SET firstname = ?, lastname = ?, age = ?, version = version + 1 WHERE id = ? AND version = ?;"
+	}
+
+	n, err := controllers.Exec(ctx, db, q, p.Firstname, p.Lastname, p.Age, id, version)
+	if err != nil {
+		return controllers.Rejected(ctx, err, controllers.BadRequest("The participant was rejected, not valid"))
+	}
+	if n == 0 {
+		return controllers.PreconditionFailed("The participant was modified by another request")
+	}
+	return nil
+}
+
+// DeleteParticipant deletes the participant and its tickets
+func DeleteParticipant(ctx context.Context, db database.Preparer, id int64, expected *uint32) error {
+	return remove(ctx, db, "participants", id, expected, "Participant not found", "The participant was modified by another request")
+}
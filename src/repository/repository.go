@@ -0,0 +1,158 @@
+// Package repository reads and writes the events, participants and
+// tickets for GraphQL, gRPC and the REST routes, so they are checked the
+// same way whatever the API. The REST collections, the ticket views, the
+// exports and the batches keep their own queries, they stream rows or run
+// in savepoints. The errors are the ones of the controllers package, each
+// API renders them with the status the REST routes would respond with.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// Page checks a limit and an offset as controllers.Page checks the query
+// params, a zero limit is the default one
+func Page(limit, offset int) (models.Pagination, error) {
+	var page = models.Pagination{Limit: constants.DefaultPageLimit, Offset: offset}
+
+	if limit != 0 {
+		if limit < 1 || limit > constants.MaxPageLimit {
+			return page, controllers.Invalid("The limit is not valid", models.FieldError{
+				Field:   "limit",
+				Message: "must be an integer between 1 and " + strconv.Itoa(constants.MaxPageLimit),
+			})
+		}
+		page.Limit = limit
+	}
+
+	if offset < 0 {
+		return page, controllers.Invalid("The offset is not valid", models.FieldError{
+			Field:   "offset",
+			Message: "must be a positive integer",
+		})
+	}
+
+	return page, nil
+}
+
+// checkVersion returns the current version of a row, compared with the
+// expected one as the If-Match header is compared by the REST routes
+func checkVersion(ctx context.Context, db database.Preparer, table string, id int64, expected *uint32, missing string) (uint32, error) {
+	version, err := controllers.Version(ctx, db, table, int(id))
+	if err == sql.ErrNoRows {
+		return 0, controllers.NotFound(missing)
+	}
+	if err != nil {
+		return 0, controllers.Internal("The version could not be retrieved", err)
+	}
+
+	switch {
+	case expected == nil && constants.RequireIfMatch:
+		return 0, controllers.PreconditionRequired("The version is required to modify this resource")
+	case expected != nil && *expected != version:
+		return 0, controllers.PreconditionFailed("The resource was modified, its current version is " + strconv.FormatUint(uint64(version), 10))
+	}
+	return version, nil
+}
+
+// remove deletes a row at the version checked by checkVersion
+func remove(ctx context.Context, db database.Preparer, table string, id int64, expected *uint32, missing, modified string) error {
+	version, err := checkVersion(ctx, db, table, id, expected, missing)
+	if err != nil {
+		return err
+	}
+
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "DELETE FROM " + table + " WHERE id = $1 AND version = $2;"
+	case storage.MySQL:
+		q = "DELETE FROM " + table + " WHERE id = ? AND version = ?;"
+	}
+
+	n, err := controllers.Exec(ctx, db, q, id, version)
+	if err != nil {
+		return controllers.Rejected(ctx, err, controllers.BadRequest("The provided ID was rejected, not valid"))
+	}
+	if n == 0 {
+		return controllers.PreconditionFailed(modified)
+	}
+	return nil
+}
+
+// lock brings the row with the ID, locked until the transaction ends
+func lock(ctx context.Context, tx database.Preparer, table, columns string, id int64, missing string, scan func(scanner) error) error {
+	var w where
+	w.add("id = ?", id)
+
+	var found bool
+	err := query(ctx, tx, "SELECT "+columns+" FROM "+table+w.String()+" FOR UPDATE;", w.args, func(s scanner) error {
+		found = true
+		return scan(s)
+	})
+	if err != nil {
+		return controllers.Internal("The "+table+" could not be retrieved", err)
+	}
+	if !found {
+		return controllers.NotFound(missing)
+	}
+	return nil
+}
+
+// insert runs an INSERT, PostgreSQL returns the row to scan and MySQL the
+// ID of the new row
+func insert(ctx context.Context, db database.Preparer, q string, scan func(scanner) error, args ...interface{}) (int64, error) {
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	if constants.Persistence == storage.PostgreSQL {
+		return 0, scan(stmt.QueryRowContext(ctx, args...))
+	}
+
+	r, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	return r.LastInsertId()
+}
+
+// list counts the rows matching w and brings a page of them
+func list(ctx context.Context, db database.Preparer, table, columns string, w where, page *models.Pagination, desc bool, scan func(scanner) error) error {
+	var err error
+
+	page.Total, err = controllers.Count(ctx, db, "SELECT COUNT(*) FROM "+table+w.String()+";", w.args...)
+	if err != nil {
+		return controllers.Internal("The "+table+" could not be counted", err)
+	}
+
+	if err = query(ctx, db, w.page("SELECT "+columns+" FROM "+table, page.Limit, page.Offset, desc), w.args, scan); err != nil {
+		return controllers.Internal("There was an error when tried to bring the "+table, err)
+	}
+	return nil
+}
+
+// byID brings the rows with the given IDs, in any order
+func byID(ctx context.Context, db database.Preparer, table, columns string, ids []int64, scan func(scanner) error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var w where
+	w.in("id", ids)
+
+	if err := query(ctx, db, "SELECT "+columns+" FROM "+table+w.String()+";", w.args, scan); err != nil {
+		return controllers.Internal("The "+table+" could not be retrieved", err)
+	}
+	return nil
+}
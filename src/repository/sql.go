@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+const (
+	eventColumns       = "id, name, created_at, starts_at, ends_at, version"
+	participantColumns = "id, firstname, lastname, age, version"
+	ticketColumns      = "id, participant, event, version"
+)
+
+// where builds the conditions of a filter, the placeholders of each one
+// are numbered as the dialect expects
+type where struct {
+	conds []string
+	args  []interface{}
+}
+
+// add takes a condition with a ? for each one of its arguments
+func (w *where) add(cond string, args ...interface{}) {
+	for _, arg := range args {
+		w.args = append(w.args, arg)
+		cond = strings.Replace(cond, "?", placeholder(len(w.args)), 1)
+	}
+	w.conds = append(w.conds, cond)
+}
+
+// in matches the column against a list of IDs, the loaders' batches
+func (w *where) in(column string, ids []int64) {
+	var marks = make([]string, len(ids))
+	for i, id := range ids {
+		w.args = append(w.args, id)
+		marks[i] = placeholder(len(w.args))
+	}
+	w.conds = append(w.conds, column+" IN ("+strings.Join(marks, ", ")+")")
+}
+
+func (w *where) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// page appends the order, limit and offset to a SELECT
+func (w *where) page(q string, limit, offset int, desc bool) string {
+	var order = " ORDER BY id ASC"
+	if desc {
+		order = " ORDER BY id DESC"
+	}
+
+	w.args = append(w.args, limit, offset)
+	return q + w.String() + order + " LIMIT " + placeholder(len(w.args)-1) + " OFFSET " + placeholder(len(w.args)) + ";"
+}
+
+func placeholder(n int) string {
+	if constants.Persistence == storage.MySQL {
+		return "?"
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// contains is the LIKE pattern of a substring, its wildcards escaped
+func contains(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(s))
+	return "%" + s + "%"
+}
+
+// query runs a SELECT and calls scan for each row
+func query(ctx context.Context, db database.Preparer, q string, args []interface{}, scan func(scanner) error) error {
+	stmt, err := db.PrepareContext(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEvent(s scanner) (models.Event, error) {
+	var e models.Event
+	err := s.Scan(&e.Id, &e.Name, &e.Created_at, &e.Starts_at, &e.Ends_at, &e.Version)
+	return e, err
+}
+
+func scanParticipant(s scanner) (models.Participant, error) {
+	var p models.Participant
+	err := s.Scan(&p.Id, &p.Firstname, &p.Lastname, &p.Age, &p.Version)
+	return p, err
+}
+
+func scanTicket(s scanner) (models.Ticket, error) {
+	var t models.Ticket
+	err := s.Scan(&t.Id, &t.Participant, &t.Event, &t.Version)
+	return t, err
+}
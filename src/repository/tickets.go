@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/metrics"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+)
+
+// TicketFilter of ListTickets, the tickets of an event, of a participant
+// or both
+type TicketFilter struct {
+	Event       *int64
+	Participant *int64
+}
+
+func (f TicketFilter) where() where {
+	var w where
+	if f.Event != nil {
+		w.add("event = ?", *f.Event)
+	}
+	if f.Participant != nil {
+		w.add("participant = ?", *f.Participant)
+	}
+	return w
+}
+
+// ListTickets brings a page of the tickets, the total of the page is set
+func ListTickets(ctx context.Context, db database.Preparer, f TicketFilter, page *models.Pagination, desc bool) ([]models.Ticket, error) {
+	var tickets = make([]models.Ticket, 0)
+
+	err := list(ctx, db, "tickets", ticketColumns, f.where(), page, desc, func(s scanner) error {
+		t, err := scanTicket(s)
+		tickets = append(tickets, t)
+		return err
+	})
+	return tickets, err
+}
+
+// TicketsOfEvents brings every ticket of the given events
+func TicketsOfEvents(ctx context.Context, db database.Connecter, ids []int64) ([]models.Ticket, error) {
+	return ticketsOf(ctx, db, "event", ids)
+}
+
+// TicketsOfParticipants brings every ticket of the given participants
+func TicketsOfParticipants(ctx context.Context, db database.Connecter, ids []int64) ([]models.Ticket, error) {
+	return ticketsOf(ctx, db, "participant", ids)
+}
+
+func ticketsOf(ctx context.Context, db database.Preparer, column string, ids []int64) ([]models.Ticket, error) {
+	var tickets = make([]models.Ticket, 0)
+	if len(ids) == 0 {
+		return tickets, nil
+	}
+
+	var w where
+	w.in(column, ids)
+
+	err := query(ctx, db, "SELECT "+ticketColumns+" FROM tickets"+w.String()+" ORDER BY id ASC;", w.args, func(s scanner) error {
+		t, err := scanTicket(s)
+		tickets = append(tickets, t)
+		return err
+	})
+	if err != nil {
+		return nil, controllers.Internal("The tickets could not be retrieved", err)
+	}
+	return tickets, nil
+}
+
+func GetTicket(ctx context.Context, db database.Preparer, id int64) (models.Ticket, error) {
+	var tickets = make([]models.Ticket, 0, 1)
+
+	err := byID(ctx, db, "tickets", ticketColumns, []int64{id}, func(s scanner) error {
+		t, err := scanTicket(s)
+		tickets = append(tickets, t)
+		return err
+	})
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	if len(tickets) == 0 {
+		return models.Ticket{}, controllers.NotFound("Ticket not found")
+	}
+	return tickets[0], nil
+}
+
+// CreateTicket registers the participant for the event
+func CreateTicket(ctx context.Context, db database.Preparer, event, participant int64) (models.Ticket, error) {
+	if err := CheckTicket(ctx, db, event, participant, 0); err != nil {
+		return models.Ticket{}, err
+	}
+
+	var (
+		ticket = models.Ticket{Event: uint16(event), Participant: uint64(participant)}
+		q      string
+	)
+
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "INSERT INTO tickets(participant, event) VALUES($1, $2) RETURNING id, version;"
+	case storage.MySQL:
+		q = "INSERT INTO tickets(participant, event) VALUES(?, ?);"
+	}
+
+	id, err := insert(ctx, db, q, func(s scanner) error {
+		return s.Scan(&ticket.Id, &ticket.Version)
+	}, participant, event)
+	if err != nil {
+		return models.Ticket{}, controllers.Rejected(ctx, err, controllers.BadRequest("The ticket was rejected, not valid"))
+	}
+
+	if constants.Persistence == storage.MySQL {
+		ticket.Id, ticket.Version = uint32(id), 1 // Default version
+	}
+	metrics.TicketsCreated.Inc()
+	return ticket, nil
+}
+
+// UpdateTicket moves the ticket to another event or participant if it's
+// at the expected version, or at any one if nil
+func UpdateTicket(ctx context.Context, db database.Preparer, id, event, participant int64, expected *uint32) (models.Ticket, error) {
+	version, err := checkVersion(ctx, db, "tickets", id, expected, "Ticket not found")
+	if err != nil {
+		return models.Ticket{}, err
+	}
+
+	if err = CheckTicket(ctx, db, event, participant, id); err != nil {
+		return models.Ticket{}, err
+	}
+
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "UPDATE tickets SET participant = $1, event = $2, version = version + 1 WHERE id = $3 AND version = $4;"
+	case storage.MySQL:
+		q = "UPDATE tickets SET participant = ?, event = ?, version = version + 1 WHERE id = ? AND version = ?;"
+	}
+
+	n, err := controllers.Exec(ctx, db, q, participant, event, id, version)
+	if err != nil {
+		return models.Ticket{}, controllers.Rejected(ctx, err, controllers.BadRequest("The ticket was rejected, not valid"))
+	}
+	if n == 0 {
+		return models.Ticket{}, controllers.PreconditionFailed("The ticket was modified by another request")
+	}
+
+	return models.Ticket{Id: uint32(id), Event: uint16(event), Participant: uint64(participant), Version: version + 1}, nil
+}
+
+func DeleteTicket(ctx context.Context, db database.Preparer, id int64, expected *uint32) error {
+	return remove(ctx, db, "tickets", id, expected, "Ticket not found", "The ticket was modified by another request")
+}
+
+// CheckTicket checks that the event and the participant exist and that the
+// participant has no other ticket for the event than the one with the ID
+func CheckTicket(ctx context.Context, db database.Preparer, event, participant, id int64) error {
+	if _, err := GetEvent(ctx, db, event); err != nil {
+		return err
+	}
+	if _, err := GetParticipant(ctx, db, participant); err != nil {
+		return err
+	}
+
+	var q string
+	switch constants.Persistence {
+	case storage.PostgreSQL:
+		q = "SELECT EXISTS (SELECT 1 FROM tickets WHERE event = $1 AND participant = $2 AND id <> $3);"
+	case storage.MySQL:
+		q = "SELECT EXISTS (SELECT 1 FROM tickets WHERE event = ? AND participant = ? AND id <> ?);"
+	}
+
+	exists, err := controllers.Exists(ctx, db, q, event, participant, id)
+	if err != nil {
+		return controllers.Internal("The tickets of the participant could not be checked", err)
+	}
+	if exists {
+		return controllers.Conflict("The participant was already registered for the event previously")
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/luisnquin/restapi-technical-test/src/controllers/docs"
+)
+
+func ApplyDocs(g *echo.Group) {
+	g.GET("/openapi.json", docs.OpenAPI()).Name = "docs.openapi"
+	g.GET("/docs", docs.UI()).Name = "docs.ui"
+}
+
+// ApplyMetrics registers the Prometheus endpoint, it tells the routes, the
+// load and the errors of the API so it's not for its clients
+func ApplyMetrics(g *echo.Group, m ...echo.MiddlewareFunc) {
+	g.GET("/metrics", echo.WrapHandler(promhttp.Handler()), m...).Name = "metrics"
+}
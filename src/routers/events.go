@@ -2,18 +2,30 @@ package routers
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
 	"github.com/luisnquin/restapi-technical-test/src/controllers/events"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
 )
 
+// Deleting the participants of an event also deletes their tickets for
+// other events, only admins can do it
 func ApplyEvents(g *echo.Group) {
-	g.GET("s", events.Fetch())
-	g.GET("/:id", events.ById())
-	g.GET("/:id/tickets", events.FetchTicketsById())
-	g.GET("/:id/participants", events.FetchParticipantsById())
-	g.GET("/:event-id/participant/:participant-id", events.FetchParticipantByIds())
-	g.POST("", events.New())
-	g.POST("/:event-id/participant/:participant-id", events.NewParticipantByIds())
-	g.PUT("/:id", events.UpdateById())
-	g.DELETE("/:id", events.RemoveById())
-	g.DELETE("/:id/participants", events.RemoveByIdWithParticipants())
+	var (
+		item       = middleware.Deadline(deadline.Item)
+		collection = middleware.Deadline(deadline.Collection)
+	)
+	g.GET("s", events.Fetch(), collection, middleware.Allow(auth.Read)).Name = "events.list"
+	g.GET("s.ics", events.Feed(), collection, middleware.Allow(auth.Read)).Name = "events.calendar"
+	g.GET("/:id", controllers.WithExtension(".ics", events.ById(), events.Calendar()), item, middleware.Allow(auth.Read)).Name = "events.get"
+	g.GET("/:id/tickets", events.FetchTicketsById(), collection, middleware.Allow(auth.Read)).Name = "events.tickets.list"
+	g.GET("/:id/participants", events.FetchParticipantsById(), collection, middleware.Allow(auth.Read)).Name = "events.participants.list"
+	g.GET("/:event-id/participant/:participant-id", events.FetchParticipantByIds(), item, middleware.Allow(auth.Read)).Name = "events.tickets.get"
+	g.POST("", events.New(), item, middleware.Allow(auth.CreateEvents), middleware.Idempotency()).Name = "events.create"
+	g.POST("/:event-id/participant/:participant-id", events.NewParticipantByIds(), item, middleware.Allow(auth.RegisterTickets, middleware.EventParam("event-id")), middleware.Idempotency()).Name = "tickets.create"
+	g.PUT("/:id", events.UpdateById(), item, middleware.Allow(auth.ManageEvents, middleware.EventParam("id"))).Name = "events.update"
+	g.PATCH("/:id", events.ModifyById(), item, middleware.Allow(auth.ManageEvents, middleware.EventParam("id"))).Name = "events.modify"
+	g.DELETE("/:id", events.RemoveById(), item, middleware.Allow(auth.ManageEvents, middleware.EventParam("id"))).Name = "events.delete"
+	g.DELETE("/:id/participants", events.RemoveByIdWithParticipants(), item, middleware.Allow(auth.Administer)).Name = "events.participants.delete"
 }
@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers/graph"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
+)
+
+// The mutations check the role of the principal themselves
+func ApplyGraph(g *echo.Group) {
+	g.POST("", graph.Query(), middleware.Deadline(deadline.Collection), middleware.Allow(auth.Read)).Name = "graphql.query"
+}
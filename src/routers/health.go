@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/controllers/health"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
+)
+
+// ApplyHealth registers the probes, without credentials nor rate limits
+func ApplyHealth(g *echo.Group) {
+	g.GET("/healthz", health.Live()).Name = "health.live"
+	g.GET("/readyz", health.Ready(), middleware.Deadline(deadline.Check)).Name = "health.ready"
+	g.GET("/version", health.Version()).Name = "version"
+}
@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/controllers/imports"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
+)
+
+// The imported registrations can be for any event
+func ApplyImports(g *echo.Group) {
+	g.POST("/participants", imports.Participants(), middleware.Deadline(deadline.Import), middleware.Allow(auth.Administer)).Name = "import.participants"
+}
@@ -2,14 +2,29 @@ package routers
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/luisnquin/restapi-technical-test/src/auth"
 	"github.com/luisnquin/restapi-technical-test/src/controllers/participants"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
 )
 
+// A participant can only be managed by the organizers of every event it
+// has a ticket for, the batches span many of them and are left to admins
 func ApplyParticipants(g *echo.Group) {
-	g.GET("s", participants.Fetch())
-	g.GET("/:id", participants.FetchById())
-	g.GET("/:id/tickets", participants.FetchTicketsById())
-	g.POST("", participants.New())
-	g.PUT("/:id", participants.UpdateById())
-	g.DELETE("/:id", participants.RemoveById())
+	var (
+		item       = middleware.Deadline(deadline.Item)
+		collection = middleware.Deadline(deadline.Collection)
+		batch      = middleware.Deadline(deadline.Batch)
+	)
+	g.GET("s", participants.Fetch(), collection, middleware.Allow(auth.Read)).Name = "participants.list"
+	g.GET("/:id", participants.FetchById(), item, middleware.Allow(auth.Read)).Name = "participants.get"
+	g.GET("/:id/tickets", participants.FetchTicketsById(), collection, middleware.Allow(auth.Read)).Name = "participants.tickets.list"
+	g.GET("/:id/agenda.ics", participants.Agenda(), collection, middleware.Allow(auth.Read)).Name = "participants.agenda"
+	g.POST("", participants.New(), item, middleware.Allow(auth.CreateParticipants), middleware.Idempotency()).Name = "participants.create"
+	g.POST("s\\:batch", participants.NewBatch(), batch, middleware.Allow(auth.CreateParticipants), middleware.Idempotency()).Name = "participants.batch.create"
+	g.POST("s\\:batchUpdate", participants.UpdateBatch(), batch, middleware.Allow(auth.Administer), middleware.Idempotency()).Name = "participants.batch.update"
+	g.POST("s\\:batchDelete", participants.RemoveBatch(), batch, middleware.Allow(auth.Administer), middleware.Idempotency()).Name = "participants.batch.delete"
+	g.PUT("/:id", participants.UpdateById(), item, middleware.Allow(auth.ManageParticipants, middleware.ParticipantParam("id"))).Name = "participants.update"
+	g.PATCH("/:id", participants.ModifyById(), item, middleware.Allow(auth.ManageParticipants, middleware.ParticipantParam("id"))).Name = "participants.modify"
+	g.DELETE("/:id", participants.RemoveById(), item, middleware.Allow(auth.ManageParticipants, middleware.ParticipantParam("id"))).Name = "participants.delete"
 }
@@ -2,10 +2,21 @@ package routers
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/luisnquin/restapi-technical-test/src/auth"
 	"github.com/luisnquin/restapi-technical-test/src/controllers/persistence"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
 )
 
+// The help is public
 func ApplyPersistence(g *echo.Group) {
-	g.GET("/help", persistence.Help())
-	g.POST("/build/:persistence", persistence.Build())
+	g.GET("/help", persistence.Help()).Name = "persistence.help"
+}
+
+// Building the database drops every table, it's for admins and audited,
+// the middlewares restrict who can reach it, see ApplyAdmin
+func ApplyBuild(g *echo.Group, m ...echo.MiddlewareFunc) {
+	m = append([]echo.MiddlewareFunc{middleware.Audit("persistence.build")}, m...)
+	m = append(m, middleware.Allow(auth.Administer))
+
+	g.POST("/build/:persistence", persistence.Build(), m...).Name = "persistence.build"
 }
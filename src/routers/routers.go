@@ -1,13 +1,45 @@
 package routers
 
-import "github.com/labstack/echo/v4"
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
+	"github.com/luisnquin/restapi-technical-test/src/ratelimit"
+)
 
 func Apply(e *echo.Echo) {
+	docs := e.Group("")
+	ApplyDocs(docs)
+	ApplyHealth(docs)
+
+	// Loopback only unless there's an admin listener
+	if constants.AdminAddress == "" {
+		ApplyMetrics(docs, middleware.Loopback())
+	}
+
+	// Token buckets per IP before the credentials are checked, so bad ones
+	// can't flood the checks, and per principal once they are
+	limits := ratelimit.Default
+	limitIP := middleware.RateLimit(limits, middleware.Always(ratelimit.IPs))
+	limit := middleware.RateLimit(limits, middleware.ByMethod(ratelimit.Reads, ratelimit.Writes))
+
+	// API keys and bearer tokens, the same for every protected route
+	authenticate := middleware.Authenticate()
+
 	persistence := e.Group("/persistence")
 	ApplyPersistence(persistence)
 
+	// Loopback only unless it has its own listener
+	if constants.PersistenceBuild && constants.AdminAddress == "" {
+		ApplyBuild(persistence, middleware.Loopback(), limitIP, authenticate, middleware.RateLimit(limits, middleware.Always(ratelimit.Persistence)))
+	}
+
+	graph := e.Group("/graphql", limitIP, authenticate, middleware.RateLimit(limits, middleware.ByOperation(ratelimit.Reads, ratelimit.Writes)))
+	ApplyGraph(graph)
+
 	api := e.Group("/api")
-	v1 := api.Group("/v1")
+	v1 := api.Group("/v1", limitIP, authenticate, limit)
 
 	event := v1.Group("/event")
 	ApplyEvents(event)
@@ -17,4 +49,17 @@ func Apply(e *echo.Echo) {
 
 	ticket := v1.Group("/ticket")
 	ApplyTickets(ticket)
+
+	imports := v1.Group("/import")
+	ApplyImports(imports)
+}
+
+// ApplyAdmin registers the routes of the admin listener, ADMIN_ADDRESS
+func ApplyAdmin(e *echo.Echo) {
+	ApplyMetrics(e.Group(""))
+
+	if constants.PersistenceBuild {
+		persistence := e.Group("/persistence")
+		ApplyBuild(persistence, middleware.RateLimit(ratelimit.Default, middleware.Always(ratelimit.IPs)), middleware.Authenticate(), middleware.RateLimit(ratelimit.Default, middleware.Always(ratelimit.Persistence)))
+	}
 }
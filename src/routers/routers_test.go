@@ -0,0 +1,46 @@
+package routers
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers/docs"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
+)
+
+// Every route of each listener must have its entry in the OpenAPI document
+func TestRoutesAreDocumented(t *testing.T) {
+	build, address := constants.PersistenceBuild, constants.AdminAddress
+	t.Cleanup(func() {
+		constants.PersistenceBuild, constants.AdminAddress = build, address
+	})
+
+	tests := []struct {
+		name    string
+		address string
+		apply   func(e *echo.Echo)
+	}{
+		{name: "server", apply: Apply},
+		{name: "server with an admin listener", address: "127.0.0.1:8001", apply: Apply},
+		{name: "admin", address: "127.0.0.1:8001", apply: ApplyAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constants.PersistenceBuild, constants.AdminAddress = true, tt.address
+
+			e := echo.New()
+			middleware.Apply(e)
+			tt.apply(e)
+
+			if len(e.Routes()) == 0 {
+				t.Fatal("No routes were registered")
+			}
+			for _, route := range docs.Undocumented(e.Routes()) {
+				t.Errorf("%s has no entry in the OpenAPI document, add it to controllers/docs/operations.go", route)
+			}
+		})
+	}
+}
@@ -2,14 +2,26 @@ package routers
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/luisnquin/restapi-technical-test/src/auth"
 	"github.com/luisnquin/restapi-technical-test/src/controllers/tickets"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/middleware"
 )
 
+// A ticket belongs to the organizers of its event, the one it's moved to
+// included
 func ApplyTickets(g *echo.Group) {
-	g.GET("s", tickets.FetchTickets())
-	g.GET("/:id", tickets.FetchById())
-	g.POST("", tickets.NewTicket())
-	g.PATCH("/:id", tickets.ModifyTicketById())
-	g.PUT("/:id", tickets.UpdateTicketById())
-	g.DELETE("/:id", tickets.RemoveTicketById())
+	var (
+		item       = middleware.Deadline(deadline.Item)
+		collection = middleware.Deadline(deadline.Collection)
+		batch      = middleware.Deadline(deadline.Batch)
+	)
+	g.GET("s", tickets.FetchTickets(), collection, middleware.Allow(auth.Read)).Name = "tickets.list"
+	g.GET("/:id", tickets.FetchById(), item, middleware.Allow(auth.Read)).Name = "tickets.get"
+	g.POST("", tickets.NewTicket(), item, middleware.Allow(auth.RegisterTickets, middleware.TicketBody()), middleware.Idempotency()).Name = "tickets.create"
+	g.POST("s\\:batch", tickets.NewBatch(), batch, middleware.Allow(auth.Administer), middleware.Idempotency()).Name = "tickets.batch.create"
+	g.POST("s\\:batchDelete", tickets.RemoveBatch(), batch, middleware.Allow(auth.Administer), middleware.Idempotency()).Name = "tickets.batch.delete"
+	g.PATCH("/:id", tickets.ModifyTicketById(), item, middleware.Allow(auth.ManageTickets, middleware.TicketParam("id"), middleware.TicketBody())).Name = "tickets.modify"
+	g.PUT("/:id", tickets.UpdateTicketById(), item, middleware.Allow(auth.ManageTickets, middleware.TicketParam("id"), middleware.TicketBody())).Name = "tickets.update"
+	g.DELETE("/:id", tickets.RemoveTicketById(), item, middleware.Allow(auth.ManageTickets, middleware.TicketParam("id"))).Name = "tickets.delete"
 }
@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	restapiv1 "github.com/luisnquin/restapi-technical-test/src/proto/restapi/v1"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+)
+
+type eventService struct {
+	restapiv1.UnimplementedEventServiceServer
+}
+
+func (eventService) ListEvents(ctx context.Context, req *restapiv1.ListEventsRequest) (*restapiv1.ListEventsResponse, error) {
+	page, desc, err := page(req.GetPage())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		f      = req.GetFilter()
+		filter = repository.EventFilter{StartsAfter: timeOf(f.GetStartsAfter()), StartsBefore: timeOf(f.GetStartsBefore())}
+	)
+	if f != nil {
+		filter.Name, filter.Scheduled = f.Name, f.Scheduled
+	}
+
+	var events []models.Event
+	err = withDB(ctx, func(ctx context.Context, db database.Connecter) error {
+		events, err = repository.ListEvents(ctx, db, filter, &page, desc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res = &restapiv1.ListEventsResponse{Items: make([]*restapiv1.Event, len(events)), Pagination: pagination(page)}
+	for i, e := range events {
+		res.Items[i] = eventOf(e)
+	}
+	return res, nil
+}
+
+func (eventService) GetEvent(ctx context.Context, req *restapiv1.GetEventRequest) (*restapiv1.Event, error) {
+	var event models.Event
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		event, err = repository.GetEvent(ctx, db, req.GetId())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventOf(event), nil
+}
+
+func (eventService) CreateEvent(ctx context.Context, req *restapiv1.CreateEventRequest) (*restapiv1.Event, error) {
+	var event models.Event
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		if err = repository.Authorize(ctx, db, auth.CreateEvents); err != nil {
+			return err
+		}
+		event, err = repository.CreateEvent(ctx, db, eventInput(req.GetEvent()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventOf(event), nil
+}
+
+func (eventService) UpdateEvent(ctx context.Context, req *restapiv1.UpdateEventRequest) (*restapiv1.Event, error) {
+	var event models.Event
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		if err = repository.Authorize(ctx, db, auth.ManageEvents, repository.OwnEvent(req.GetId())); err != nil {
+			return err
+		}
+		event, err = repository.UpdateEvent(ctx, db, req.GetId(), eventInput(req.GetEvent()), versionOf(req.Version != nil, req.GetVersion()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventOf(event), nil
+}
+
+func (eventService) DeleteEvent(ctx context.Context, req *restapiv1.DeleteEventRequest) (*emptypb.Empty, error) {
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) error {
+		if err := repository.Authorize(ctx, db, auth.ManageEvents, repository.OwnEvent(req.GetId())); err != nil {
+			return err
+		}
+		return repository.DeleteEvent(ctx, db, req.GetId(), versionOf(req.Version != nil, req.GetVersion()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func eventOf(e models.Event) *restapiv1.Event {
+	return &restapiv1.Event{
+		Id:        int64(e.Id),
+		Name:      e.Name,
+		CreatedAt: timestampOf(&e.Created_at),
+		StartsAt:  timestampOf(e.Starts_at),
+		EndsAt:    timestampOf(e.Ends_at),
+		Version:   e.Version,
+	}
+}
+
+func eventInput(in *restapiv1.EventInput) models.Event {
+	return models.Event{Name: in.GetName(), Starts_at: timeOf(in.GetStartsAt()), Ends_at: timeOf(in.GetEndsAt())}
+}
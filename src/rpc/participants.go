@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	restapiv1 "github.com/luisnquin/restapi-technical-test/src/proto/restapi/v1"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+)
+
+type participantService struct {
+	restapiv1.UnimplementedParticipantServiceServer
+}
+
+func (participantService) ListParticipants(ctx context.Context, req *restapiv1.ListParticipantsRequest) (*restapiv1.ListParticipantsResponse, error) {
+	page, desc, err := page(req.GetPage())
+	if err != nil {
+		return nil, err
+	}
+
+	var filter repository.ParticipantFilter
+	if f := req.GetFilter(); f != nil {
+		filter.Name = f.Name
+		if f.MinAge != nil {
+			var age = int(f.GetMinAge())
+			filter.MinAge = &age
+		}
+		if f.MaxAge != nil {
+			var age = int(f.GetMaxAge())
+			filter.MaxAge = &age
+		}
+	}
+
+	var participants []models.Participant
+	err = withDB(ctx, func(ctx context.Context, db database.Connecter) error {
+		participants, err = repository.ListParticipants(ctx, db, filter, &page, desc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res = &restapiv1.ListParticipantsResponse{Items: make([]*restapiv1.Participant, len(participants)), Pagination: pagination(page)}
+	for i, p := range participants {
+		res.Items[i] = participantOf(p)
+	}
+	return res, nil
+}
+
+func (participantService) GetParticipant(ctx context.Context, req *restapiv1.GetParticipantRequest) (*restapiv1.Participant, error) {
+	var participant models.Participant
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		participant, err = repository.GetParticipant(ctx, db, req.GetId())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return participantOf(participant), nil
+}
+
+func (participantService) CreateParticipant(ctx context.Context, req *restapiv1.CreateParticipantRequest) (*restapiv1.Participant, error) {
+	var participant models.Participant
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		if err = repository.Authorize(ctx, db, auth.CreateParticipants); err != nil {
+			return err
+		}
+		participant, err = repository.CreateParticipant(ctx, db, participantInput(req.GetParticipant()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return participantOf(participant), nil
+}
+
+func (participantService) UpdateParticipant(ctx context.Context, req *restapiv1.UpdateParticipantRequest) (*restapiv1.Participant, error) {
+	var participant models.Participant
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		if err = repository.Authorize(ctx, db, auth.ManageParticipants, repository.OwnParticipant(req.GetId())); err != nil {
+			return err
+		}
+		participant, err = repository.UpdateParticipant(ctx, db, req.GetId(), participantInput(req.GetParticipant()), versionOf(req.Version != nil, req.GetVersion()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return participantOf(participant), nil
+}
+
+func (participantService) DeleteParticipant(ctx context.Context, req *restapiv1.DeleteParticipantRequest) (*emptypb.Empty, error) {
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) error {
+		if err := repository.Authorize(ctx, db, auth.ManageParticipants, repository.OwnParticipant(req.GetId())); err != nil {
+			return err
+		}
+		return repository.DeleteParticipant(ctx, db, req.GetId(), versionOf(req.Version != nil, req.GetVersion()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func participantOf(p models.Participant) *restapiv1.Participant {
+	return &restapiv1.Participant{
+		Id:        int64(p.Id),
+		Firstname: p.Firstname,
+		Lastname:  p.Lastname,
+		Age:       uint32(p.Age),
+		Version:   p.Version,
+	}
+}
+
+// Ages out of the range of the column are left to Validate
+func participantInput(in *restapiv1.ParticipantInput) models.Participant {
+	var p = models.Participant{Firstname: in.GetFirstname(), Lastname: in.GetLastname()}
+	if in.GetAge() < 256 {
+		p.Age = uint8(in.GetAge())
+	}
+	return p
+}
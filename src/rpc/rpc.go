@@ -0,0 +1,390 @@
+// Package rpc serves the events, participants and tickets over gRPC, next
+// to the REST API and on its own address. The services are the ones of
+// proto/restapi/v1 and share the repository with the GraphQL endpoint.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/controllers"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/deadline"
+	"github.com/luisnquin/restapi-technical-test/src/logging"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	restapiv1 "github.com/luisnquin/restapi-technical-test/src/proto/restapi/v1"
+	"github.com/luisnquin/restapi-technical-test/src/ratelimit"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+	"github.com/luisnquin/restapi-technical-test/src/storage"
+	"github.com/luisnquin/restapi-technical-test/src/tracing"
+)
+
+// New registers the services, the health one and the reflection one, the
+// latter for clients like grpcurl
+func New() *grpc.Server {
+	var server = grpc.NewServer(grpc.ChainUnaryInterceptor(
+		traceInterceptor, logInterceptor, recoverInterceptor, deadlineInterceptor, unaryInterceptor,
+		rateLimitInterceptor(ratelimit.Default, func(string) ratelimit.Policy { return ratelimit.IPs }),
+		authInterceptor(auth.Default()),
+		rateLimitInterceptor(ratelimit.Default, policyOf),
+	))
+
+	restapiv1.RegisterEventServiceServer(server, eventService{})
+	restapiv1.RegisterParticipantServiceServer(server, participantService{})
+	restapiv1.RegisterTicketServiceServer(server, ticketService{})
+
+	var checker = health.NewServer()
+	checker.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	for name := range server.GetServiceInfo() {
+		checker.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(server, checker)
+	reflection.Register(server)
+
+	return server
+}
+
+// Serve listens on the address and blocks until the server stops
+func Serve(server *grpc.Server, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// traceInterceptor starts a span per call, child of the one of the
+// traceparent metadata if the client sends it
+func traceInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, strings.TrimPrefix(info.FullMethod, "/"), trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.method", info.FullMethod),
+	))
+	defer span.End()
+
+	res, err := handler(ctx, req)
+
+	var code = status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	switch code {
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	return res, err
+}
+
+// metadataCarrier reads the trace context of the incoming metadata
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	if v := metadata.MD(m).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// logInterceptor keeps the x-request-id of the client, if it's valid, or
+// makes a new one, as the RequestID middleware does, and writes a record
+// per call
+func logInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var start = time.Now()
+
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			id = v[0]
+		}
+	}
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	ctx = logging.WithRequestID(ctx, id)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+
+	res, err := handler(ctx, req)
+
+	var (
+		code  = status.Code(err)
+		level = slog.LevelInfo
+	)
+	switch code {
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+		level = slog.LevelError
+	}
+	slog.LogAttrs(ctx, level, "rpc",
+		slog.String("method", info.FullMethod),
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+	)
+	return res, err
+}
+
+// recoverInterceptor turns the panics of the calls into Internal, logged
+// with their stack as the Recover middleware does, instead of stopping the
+// server
+func recoverInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		slog.ErrorContext(ctx, "panic", "method", info.FullMethod, "error", fmt.Sprint(r), "stack", string(debug.Stack()))
+		res, err = nil, status.Error(codes.Internal, "The call could not be completed")
+	}()
+	return handler(ctx, req)
+}
+
+// deadlineInterceptor gives each call the deadline of its kind of REST
+// route, the List methods the one of the collections and the rest the one
+// of the items. A shorter deadline of the client is kept.
+func deadlineInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, budgetOf(info.FullMethod))
+	defer cancel()
+
+	return handler(ctx, req)
+}
+
+// budgetOf the method, e.g. /restapi.v1.EventService/ListEvents
+func budgetOf(method string) time.Duration {
+	name := method[strings.LastIndex(method, "/")+1:]
+
+	switch {
+	case strings.HasPrefix(method, "/grpc.health."):
+		return deadline.Check
+	case strings.HasPrefix(name, "List"):
+		return deadline.Collection
+	default:
+		return deadline.Item
+	}
+}
+
+// unaryInterceptor converts the errors of the repository to the gRPC
+// status of the HTTP one the REST routes would respond with
+func unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	res, err := handler(ctx, req)
+	if err == nil {
+		return res, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+	return nil, statusOf(ctx, info.FullMethod, err)
+}
+
+func statusOf(ctx context.Context, method string, err error) error {
+	// The drivers don't always tell the query was interrupted
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, "The request took too long")
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return status.Error(codes.Canceled, "The request was canceled")
+	}
+
+	code, detail, fields := controllers.StatusOf(err)
+
+	var c codes.Code
+	switch code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		c = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		c = codes.Unauthenticated
+	case http.StatusForbidden:
+		c = codes.PermissionDenied
+	case http.StatusNotFound:
+		c = codes.NotFound
+	case http.StatusConflict:
+		c = codes.AlreadyExists
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		c = codes.FailedPrecondition
+	case http.StatusTooManyRequests, http.StatusRequestEntityTooLarge:
+		c = codes.ResourceExhausted
+	default:
+		slog.ErrorContext(ctx, "The call failed", "method", method, "error", err)
+		return status.Error(codes.Internal, detail)
+	}
+
+	if len(fields) == 0 {
+		return status.Error(c, detail)
+	}
+
+	var violations = make([]*errdetails.BadRequest_FieldViolation, len(fields))
+	for i, f := range fields {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message}
+	}
+
+	s, err := status.New(c, detail).WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(c, detail)
+	}
+	return s.Err()
+}
+
+// authInterceptor requires the same credentials as the REST API, sent as
+// x-api-key or authorization metadata, the health checks are public
+func authInterceptor(authenticators []auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.") {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		var h = make(http.Header, len(md))
+		for key, values := range md {
+			h[http.CanonicalHeaderKey(key)] = values
+		}
+
+		p, err := auth.Authenticate(ctx, h, authenticators...)
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials):
+			return nil, status.Error(codes.Unauthenticated, "An API key in the x-api-key metadata or a bearer token is required")
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, "The credentials are not valid")
+		case err != nil:
+			return nil, controllers.Internal("The credentials could not be checked", err)
+		}
+
+		return handler(auth.NewContext(ctx, p), req)
+	}
+}
+
+// rateLimitInterceptor takes a token of the bucket of the client, the same
+// buckets as the REST routes: the IP before the credentials are checked
+// and the principal once they are. The health checks are not limited.
+func rateLimitInterceptor(store ratelimit.Store, policyOf func(method string) ratelimit.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		policy := policyOf(info.FullMethod)
+		if policy.Off() || strings.HasPrefix(info.FullMethod, "/grpc.health.") {
+			return handler(ctx, req)
+		}
+
+		r, err := store.Take(ctx, policy.Name+":"+clientOf(ctx), policy)
+		if err != nil {
+			slog.ErrorContext(ctx, "The rate limit could not be checked", "error", err)
+			return handler(ctx, req)
+		}
+		if !r.Allowed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(r.RetryAfter.Seconds())))))
+			return nil, status.Error(codes.ResourceExhausted, "The limit of "+policy.Name+" was reached, retry after the seconds of the retry-after metadata")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// policyOf the method, the List and Get ones are reads
+func policyOf(method string) ratelimit.Policy {
+	name := method[strings.LastIndex(method, "/")+1:]
+
+	if strings.HasPrefix(name, "List") || strings.HasPrefix(name, "Get") {
+		return ratelimit.Reads
+	}
+	return ratelimit.Writes
+}
+
+// clientOf the call, its principal or else the IP of its peer, with the
+// keys of the REST routes
+func clientOf(ctx context.Context) string {
+	if p := auth.FromContext(ctx); p != nil {
+		return "sub:" + p.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:"
+}
+
+// withDB connects to the database for a single call, as the REST handlers
+// do for each request. A connection that can't be closed fails the call.
+func withDB(ctx context.Context, call func(ctx context.Context, db database.Connecter) error) (err error) {
+	var db = storage.Get(constants.Persistence)
+
+	if err = db.Connect(ctx); err != nil {
+		return controllers.Internal("Database connection failed", err)
+	}
+
+	defer func() {
+		if cerr := db.Close(); cerr != nil && err == nil {
+			err = controllers.Internal("The database connection could not be closed", cerr)
+		}
+	}()
+
+	return call(ctx, db)
+}
+
+func page(p *restapiv1.PageRequest) (models.Pagination, bool, error) {
+	page, err := repository.Page(int(p.GetLimit()), int(p.GetOffset()))
+	return page, p.GetDesc(), err
+}
+
+func pagination(page models.Pagination) *restapiv1.Pagination {
+	return &restapiv1.Pagination{Limit: int32(page.Limit), Offset: int32(page.Offset), Total: int32(page.Total)}
+}
+
+// versionOf an optional field, nil if unset
+func versionOf(set bool, v uint32) *uint32 {
+	if !set {
+		return nil
+	}
+	return &v
+}
+
+func timestampOf(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func timeOf(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+	var v = t.AsTime()
+	return &v
+}
@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/database"
+	"github.com/luisnquin/restapi-technical-test/src/models"
+	restapiv1 "github.com/luisnquin/restapi-technical-test/src/proto/restapi/v1"
+	"github.com/luisnquin/restapi-technical-test/src/repository"
+)
+
+type ticketService struct {
+	restapiv1.UnimplementedTicketServiceServer
+}
+
+func (ticketService) ListTickets(ctx context.Context, req *restapiv1.ListTicketsRequest) (*restapiv1.ListTicketsResponse, error) {
+	page, desc, err := page(req.GetPage())
+	if err != nil {
+		return nil, err
+	}
+
+	var filter repository.TicketFilter
+	if f := req.GetFilter(); f != nil {
+		filter.Event, filter.Participant = f.Event, f.Participant
+	}
+
+	var tickets []models.Ticket
+	err = withDB(ctx, func(ctx context.Context, db database.Connecter) error {
+		tickets, err = repository.ListTickets(ctx, db, filter, &page, desc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res = &restapiv1.ListTicketsResponse{Items: make([]*restapiv1.Ticket, len(tickets)), Pagination: pagination(page)}
+	for i, t := range tickets {
+		res.Items[i] = ticketOf(t)
+	}
+	return res, nil
+}
+
+func (ticketService) GetTicket(ctx context.Context, req *restapiv1.GetTicketRequest) (*restapiv1.Ticket, error) {
+	var ticket models.Ticket
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		ticket, err = repository.GetTicket(ctx, db, req.GetId())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticketOf(ticket), nil
+}
+
+func (ticketService) CreateTicket(ctx context.Context, req *restapiv1.CreateTicketRequest) (*restapiv1.Ticket, error) {
+	var ticket models.Ticket
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		if err = repository.Authorize(ctx, db, auth.RegisterTickets, repository.OwnEvent(req.GetEvent())); err != nil {
+			return err
+		}
+		ticket, err = repository.CreateTicket(ctx, db, req.GetEvent(), req.GetParticipant())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticketOf(ticket), nil
+}
+
+func (ticketService) UpdateTicket(ctx context.Context, req *restapiv1.UpdateTicketRequest) (*restapiv1.Ticket, error) {
+	var ticket models.Ticket
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) (err error) {
+		if err = repository.Authorize(ctx, db, auth.ManageTickets, repository.OwnTicket(req.GetId()), repository.OwnEvent(req.GetEvent())); err != nil {
+			return err
+		}
+		ticket, err = repository.UpdateTicket(ctx, db, req.GetId(), req.GetEvent(), req.GetParticipant(), versionOf(req.Version != nil, req.GetVersion()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticketOf(ticket), nil
+}
+
+func (ticketService) DeleteTicket(ctx context.Context, req *restapiv1.DeleteTicketRequest) (*emptypb.Empty, error) {
+	err := withDB(ctx, func(ctx context.Context, db database.Connecter) error {
+		if err := repository.Authorize(ctx, db, auth.ManageTickets, repository.OwnTicket(req.GetId())); err != nil {
+			return err
+		}
+		return repository.DeleteTicket(ctx, db, req.GetId(), versionOf(req.Version != nil, req.GetVersion()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func ticketOf(t models.Ticket) *restapiv1.Ticket {
+	return &restapiv1.Ticket{
+		Id:          int64(t.Id),
+		Event:       int64(t.Event),
+		Participant: int64(t.Participant),
+		Version:     t.Version,
+	}
+}
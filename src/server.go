@@ -1,21 +1,68 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/TwiN/go-color"
 	"github.com/labstack/echo/v4"
 
+	"github.com/luisnquin/restapi-technical-test/src/auth"
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+	"github.com/luisnquin/restapi-technical-test/src/logging"
 	"github.com/luisnquin/restapi-technical-test/src/middleware"
 	"github.com/luisnquin/restapi-technical-test/src/routers"
+	"github.com/luisnquin/restapi-technical-test/src/rpc"
+	"github.com/luisnquin/restapi-technical-test/src/tracing"
 )
 
+// Sends the spans not exported yet, before the process exits
+var flush = func(context.Context) error { return nil }
+
 func main() {
+	logging.Setup()
+
+	shutdown, err := tracing.Setup(context.Background())
+	if err != nil {
+		fatal("The traces exporter couldn't be created", err)
+	}
+	flush = shutdown
+
+	if err = auth.Setup(); err != nil {
+		fatal("The JWT settings are not valid", err)
+	}
+
+	go func() {
+		var signals = make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		exit(0)
+	}()
+
 	var server = echo.New()
 	middleware.Apply(server)
 	routers.Apply(server)
 
+	if constants.AdminAddress != "" {
+		var admin = echo.New()
+		admin.HideBanner = true
+		middleware.Apply(admin)
+		routers.ApplyAdmin(admin)
+
+		go func() {
+			fatal("The admin listener stopped", admin.Start(constants.AdminAddress))
+		}()
+	}
+
+	go func() {
+		fatal("The gRPC server stopped", rpc.Serve(rpc.New(), constants.GRPCAddress))
+	}()
+
 	go func() {
 		time.Sleep(time.Millisecond * 250)
 		fmt.Printf("Fast acccess:\n %s\n %s\n %s\n\n",
@@ -26,6 +73,27 @@ func main() {
 		fmt.Printf("First access:\n %s\n\n",
 			color.InRed(" -> http://127.0.0.1:8000/persistence/help"),
 		)
+		fmt.Printf("Documentation:\n %s\n\n",
+			color.InGreen(" -> http://127.0.0.1:8000/docs"),
+		)
+		fmt.Printf("gRPC:\n %s\n\n",
+			color.InBlue(" -> "+constants.GRPCAddress),
+		)
 	}()
-	server.Logger.Fatal(server.Start(":8000"))
+	fatal("The server stopped", server.Start(":8000"))
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	exit(1)
+}
+
+func exit(code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err := flush(ctx); err != nil {
+		slog.Error("The spans couldn't be exported", "error", err)
+	}
+	os.Exit(code)
 }
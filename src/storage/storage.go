@@ -9,6 +9,17 @@ const (
 	MySQL      Persistence = 2
 )
 
+// String is the name of the persistence in PERSISTENCE_NAME
+func (p Persistence) String() string {
+	switch p {
+	case PostgreSQL:
+		return "PostgreSQL"
+	case MySQL:
+		return "MySQL"
+	}
+	return "unknown"
+}
+
 func Get(persistence Persistence) database.Connecter {
 	switch persistence {
 	case PostgreSQL:
@@ -0,0 +1,83 @@
+// Package tracing sets up OpenTelemetry, the spans of the requests are
+// started by the Tracing middleware and the gRPC interceptor, the ones of
+// the queries by the drivers of the database package.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/luisnquin/restapi-technical-test/src/constants"
+)
+
+// Name of the tracers of the module
+const Instrumentation string = "github.com/luisnquin/restapi-technical-test/src"
+
+// Tracer of the spans started out of the database package
+func Tracer() trace.Tracer {
+	return otel.Tracer(Instrumentation)
+}
+
+// Setup makes the W3C trace context and baggage the propagators and, if
+// OTEL_TRACES_EXPORTER is set, the provider of that exporter the global
+// one. The spans not exported yet are sent with shutdown.
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, constants.TracesExporter, constants.TracesProtocol)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String("restapi"),
+			semconv.ServiceVersionKey.String(constants.APIVersion),
+		),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+		resource.WithSchemaURL(semconv.SchemaURL),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var provider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// newExporter of the name, nil for none
+func newExporter(ctx context.Context, name, protocol string) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return nil, nil
+	case "stdout", "console":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		switch strings.ToLower(protocol) {
+		case "", "grpc":
+			return otlptracegrpc.New(ctx)
+		case "http/protobuf":
+			return otlptracehttp.New(ctx)
+		default:
+			return nil, fmt.Errorf("unknown OTLP protocol %q, it must be grpc or http/protobuf", protocol)
+		}
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q, it must be otlp, stdout or none", name)
+	}
+}